@@ -0,0 +1,73 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var overrideEnv []string
+var overrideVolumes []string
+
+var composeOverrideCmd = &cobra.Command{
+	Use:   "compose-override <stack_name> <service>",
+	Short: "Customize a service in a stack's docker-compose.override.yml",
+	Long: `Customize a service in a stack's docker-compose.override.yml
+
+The override file is used alongside the stack's generated docker-compose.yml by every
+command that runs docker-compose, and is never overwritten by the CLI. This command
+sets environment variables and adds volumes for a service, keeping anything else in
+the file, which can also be edited by hand. Restart the stack to apply the changes.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(overrideEnv) == 0 && len(overrideVolumes) == 0 {
+			return fmt.Errorf("nothing to override - please specify --env or --volume")
+		}
+		for _, env := range overrideEnv {
+			if i := strings.Index(env, "="); i <= 0 {
+				return fmt.Errorf("'%s' is not a valid environment variable - please use the format KEY=VALUE", env)
+			}
+		}
+		for _, volume := range overrideVolumes {
+			if !strings.Contains(volume, ":") {
+				return fmt.Errorf("'%s' is not a valid volume - please use the format <source>:<path>[:<options>]", volume)
+			}
+		}
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+		if err := stackManager.UpdateComposeOverride(args[1], overrideEnv, overrideVolumes); err != nil {
+			return err
+		}
+		fmt.Printf("updated %s\n", filepath.Join(constants.StacksDir, args[0], docker.ComposeOverrideFileName))
+		return nil
+	},
+}
+
+func init() {
+	composeOverrideCmd.Flags().StringArrayVarP(&overrideEnv, "env", "e", []string{}, "Environment variable to set for the service, in the format KEY=VALUE. Can be specified multiple times")
+	composeOverrideCmd.Flags().StringArrayVar(&overrideVolumes, "volume", []string{}, "Volume to mount in the service, in the format <source>:<path>[:<options>]. Can be specified multiple times")
+	rootCmd.AddCommand(composeOverrideCmd)
+}
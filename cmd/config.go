@@ -0,0 +1,72 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var configFireFlyConfigPath string
+var configMemberConfigs []string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the FireFly core config of a stack",
+	Long:  `Manage the FireFly core config of a stack`,
+}
+
+var configApplyCmd = &cobra.Command{
+	Use:   "apply <stack_name>",
+	Short: "Copy each member's FireFly core config into its volume and restart it",
+	Long: `Copy each member's FireFly core config into its volume and restart it
+
+Each member's config is read from configs/firefly_core_<id>.yml in the stack's
+directory, so any changes made to those files are applied. Only members whose
+config has changed are restarted.
+If --firefly-config or --member-config are given, they replace the overrides that
+were saved when the stack was created, and each member's config is generated again
+before it is applied.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := docker.CheckDockerConfig(); err != nil {
+			return err
+		}
+		memberConfigPaths, err := parseMemberConfigs(configMemberConfigs)
+		if err != nil {
+			return err
+		}
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+		if configFireFlyConfigPath != "" || len(memberConfigPaths) > 0 {
+			if err := stackManager.UpdateConfigOverrides(configFireFlyConfigPath, memberConfigPaths); err != nil {
+				return err
+			}
+		}
+		return stackManager.ApplyFireflyConfigs(verbose)
+	},
+}
+
+func init() {
+	configApplyCmd.Flags().StringVar(&configFireFlyConfigPath, "firefly-config", "", "YAML file of FireFly core config to deep merge into the config generated for every member")
+	configApplyCmd.Flags().StringArrayVar(&configMemberConfigs, "member-config", []string{}, "YAML file of FireFly core config to deep merge into one member's config, in the format <member>=<file>. Can be specified multiple times")
+	configCmd.AddCommand(configApplyCmd)
+	rootCmd.AddCommand(configCmd)
+}
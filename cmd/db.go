@@ -0,0 +1,107 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var dbQuery string
+var dbJSON bool
+
+var dbCmd = &cobra.Command{
+	Use:   "db <stack_name> <member>",
+	Short: "Open a database shell for a member, or run a query against its database",
+	Long: `Open a database shell for a member, or run a query against its database
+
+The member can be given by its ID or its org name. Without --query, an interactive
+psql or sqlite3 session is opened against the member's database. With --query, the
+statement is run once and the result is printed as a table, or as JSON with --json.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := docker.CheckDockerConfig(); err != nil {
+			return err
+		}
+		if dbJSON && dbQuery == "" {
+			return fmt.Errorf("--json can only be used with --query")
+		}
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+		member, err := stackManager.GetMember(args[1])
+		if err != nil {
+			return err
+		}
+
+		if dbQuery == "" {
+			return stackManager.OpenDatabaseShell(member, verbose)
+		}
+		columns, rows, err := stackManager.QueryDatabase(member, dbQuery, verbose)
+		if err != nil {
+			return err
+		}
+		if dbJSON {
+			return printRowsJSON(columns, rows)
+		}
+		printRowsTable(columns, rows)
+		return nil
+	},
+}
+
+func printRowsJSON(columns []string, rows [][]string) error {
+	objects := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		object := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if i < len(row) {
+				object[column] = row[i]
+			}
+		}
+		objects = append(objects, object)
+	}
+	bytes, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
+
+func printRowsTable(columns []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	fmt.Printf("(%d rows)\n", len(rows))
+}
+
+func init() {
+	dbCmd.Flags().StringVarP(&dbQuery, "query", "q", "", "SQL statement to run instead of opening an interactive shell")
+	dbCmd.Flags().BoolVar(&dbJSON, "json", false, "Print the result of --query as JSON instead of a table")
+	rootCmd.AddCommand(dbCmd)
+}
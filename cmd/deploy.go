@@ -0,0 +1,131 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var deployName string
+var deployContractName string
+var deploySolcBasePath string
+var deployChannel string
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy a smart contract or chaincode to a running stack",
+	Long:  `Deploy a smart contract or chaincode to a running stack`,
+}
+
+var deployEthereumCmd = &cobra.Command{
+	Use:   "ethereum <stack_name> <contract_file> [constructor_args...]",
+	Short: "Deploy a Solidity contract",
+	Long: `Deploy a Solidity contract to a running ethereum based stack.
+
+The contract file is either a compiled contract JSON file containing the "abi"
+and "bytecode" of the contract, or a .sol source file which is compiled using
+solc in Docker. If a source file contains more than one contract, the one
+named with --contract (or matching the file name) is deployed, and registered
+with the name given with --name. Imports are resolved within
+--solc-base-path, which defaults to the source file's directory.
+The contract is deployed using the first member's ethconnect, and registered
+with every other member's ethconnect. Constructor arguments are passed in the
+order they are declared in the contract. The address of the deployed contract
+is printed on the last line of output.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager, err := loadStackForDeploy(args[0], stacks.GoEthereum, stacks.HyperledgerBesu, stacks.EthereumRemote)
+		if err != nil {
+			return err
+		}
+		contractAddress, err := stackManager.DeployContract(args[1], &types.DeployOptions{
+			ContractName: deployContractName,
+			Name:         deployName,
+			Args:         args[2:],
+			SolcBasePath: deploySolcBasePath,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(contractAddress)
+		return nil
+	},
+}
+
+var deployFabricCmd = &cobra.Command{
+	Use:   "fabric <stack_name> <chaincode_package>",
+	Short: "Deploy a packaged chaincode",
+	Long: `Deploy a packaged chaincode to a running fabric stack.
+
+The chaincode package must be a .tar.gz file created with 'peer lifecycle chaincode package'.
+The chaincode is installed, approved and committed on the channel given with
+--channel, which defaults to the channel FireFly uses. If a
+chaincode with the same name has already been committed, it is upgraded to the
+new package with the next sequence number.
+The name the chaincode is committed with is printed on the last line of output.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager, err := loadStackForDeploy(args[0], stacks.HyperledgerFabric)
+		if err != nil {
+			return err
+		}
+		chaincodeName, err := stackManager.DeployContract(args[1], &types.DeployOptions{
+			Name:    deployName,
+			Channel: deployChannel,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(chaincodeName)
+		return nil
+	},
+}
+
+func loadStackForDeploy(stackName string, blockchainProviders ...stacks.BlockchainProvider) (*stacks.StackManager, error) {
+	if exists, err := stacks.CheckExists(stackName); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("stack '%s' does not exist", stackName)
+	}
+
+	stackManager := stacks.NewStackManager(logger)
+	if err := stackManager.LoadStack(stackName, verbose); err != nil {
+		return nil, err
+	}
+
+	for _, blockchainProvider := range blockchainProviders {
+		if stackManager.Stack.BlockchainProvider == blockchainProvider.String() {
+			return stackManager, nil
+		}
+	}
+	return nil, fmt.Errorf("stack '%s' uses the '%s' blockchain provider, which does not support this command", stackName, stackManager.Stack.BlockchainProvider)
+}
+
+func init() {
+	deployFabricCmd.Flags().StringVar(&deployChannel, "channel", "", "Channel to commit the chaincode on. Defaults to the channel FireFly uses")
+	deployEthereumCmd.Flags().StringVar(&deployContractName, "contract", "", "Name of the contract to deploy from a Solidity source file. Defaults to the only contract in the file, or the one matching the file name")
+	deployEthereumCmd.Flags().StringVar(&deploySolcBasePath, "solc-base-path", "", "Directory that imports in a Solidity source file are resolved within. Defaults to the source file's directory")
+	deployCmd.PersistentFlags().StringVarP(&deployName, "name", "n", "", "Name to register the contract or chaincode with. Defaults to the name of the contract or the chaincode package label")
+
+	deployCmd.AddCommand(deployEthereumCmd)
+	deployCmd.AddCommand(deployFabricCmd)
+	rootCmd.AddCommand(deployCmd)
+}
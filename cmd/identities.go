@@ -0,0 +1,192 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var identityType string
+var identityMaxEnrollments int
+var identityAttributes []string
+var identitySecret string
+var identityEnroll bool
+var identityRevokeReason string
+
+var identitiesCmd = &cobra.Command{
+	Use:     "identities",
+	Aliases: []string{"identity"},
+	Short:   "Manage Fabric CA identities on a running stack",
+	Long: `Manage Fabric CA identities on a running fabric stack.
+
+Identities are managed through the fabconnect of the given member, which
+can be specified by its member ID or org name.`,
+}
+
+var identitiesRegisterCmd = &cobra.Command{
+	Use:   "register <stack_name> <member> <name>",
+	Short: "Register a new identity",
+	Long: `Register a new identity with the Fabric CA.
+
+Attributes are specified with --attribute <name>=<value>, and are included in
+the identity's enrollment certificate by default. The enrollment secret is
+printed on the last line of output, unless --enroll is set.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager, err := loadStackForIdentities(args[0])
+		if err != nil {
+			return err
+		}
+		attributes, err := parseIdentityAttributes(identityAttributes)
+		if err != nil {
+			return err
+		}
+		secret, err := stackManager.RegisterIdentity(args[1], args[2], &stacks.RegisterIdentityOptions{
+			Type:           identityType,
+			MaxEnrollments: identityMaxEnrollments,
+			Attributes:     attributes,
+		})
+		if err != nil {
+			return err
+		}
+		if identityEnroll {
+			if err := stackManager.EnrollIdentity(args[1], args[2], secret, []string{}); err != nil {
+				return err
+			}
+			fmt.Printf("identity '%s' registered and enrolled\n", args[2])
+			return nil
+		}
+		fmt.Println(secret)
+		return nil
+	},
+}
+
+var identitiesEnrollCmd = &cobra.Command{
+	Use:   "enroll <stack_name> <member> <name>",
+	Short: "Enroll a registered identity",
+	Long: `Enroll a registered identity with the Fabric CA, using the secret returned
+when it was registered.
+
+Specify --attribute <name> to require that an attribute is included in the
+enrollment certificate.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if identitySecret == "" {
+			return errors.New("the --secret flag is required to enroll an identity")
+		}
+		stackManager, err := loadStackForIdentities(args[0])
+		if err != nil {
+			return err
+		}
+		if err := stackManager.EnrollIdentity(args[1], args[2], identitySecret, identityAttributes); err != nil {
+			return err
+		}
+		fmt.Printf("identity '%s' enrolled\n", args[2])
+		return nil
+	},
+}
+
+var identitiesListCmd = &cobra.Command{
+	Use:   "list <stack_name> <member>",
+	Short: "List the identities enrolled with a member's fabconnect",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager, err := loadStackForIdentities(args[0])
+		if err != nil {
+			return err
+		}
+		identities, err := stackManager.ListIdentities(args[1])
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tMSP ID")
+		for _, identity := range identities {
+			fmt.Fprintf(w, "%s\t%s\n", identity.Name, identity.MSPID)
+		}
+		return w.Flush()
+	},
+}
+
+var identitiesRevokeCmd = &cobra.Command{
+	Use:   "revoke <stack_name> <member> <name>",
+	Short: "Revoke an identity and all of its certificates",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager, err := loadStackForIdentities(args[0])
+		if err != nil {
+			return err
+		}
+		res, err := stackManager.RevokeIdentity(args[1], args[2], identityRevokeReason)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("identity '%s' revoked (%d certificate(s))\n", args[2], len(res.RevokedCerts))
+		return nil
+	},
+}
+
+// Attributes are specified in the format <name>=<value>, and the value may contain '='
+func parseIdentityAttributes(input []string) (map[string]string, error) {
+	attributes := make(map[string]string, len(input))
+	for _, attribute := range input {
+		kv := strings.SplitN(attribute, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid attribute '%s' - attributes must be in the format <name>=<value>", attribute)
+		}
+		attributes[kv[0]] = kv[1]
+	}
+	return attributes, nil
+}
+
+func loadStackForIdentities(stackName string) (*stacks.StackManager, error) {
+	if exists, err := stacks.CheckExists(stackName); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("stack '%s' does not exist", stackName)
+	}
+	stackManager := stacks.NewStackManager(logger)
+	if err := stackManager.LoadStack(stackName, verbose); err != nil {
+		return nil, err
+	}
+	return stackManager, nil
+}
+
+func init() {
+	identitiesRegisterCmd.Flags().StringVar(&identityType, "type", "client", "Type of the identity, such as client, peer or admin")
+	identitiesRegisterCmd.Flags().IntVar(&identityMaxEnrollments, "max-enrollments", 0, "Maximum number of times the identity can be enrolled. Uses the CA's default if not set")
+	identitiesRegisterCmd.Flags().StringArrayVar(&identityAttributes, "attribute", []string{}, "Attribute to register the identity with, in the format <name>=<value>. Can be specified multiple times")
+	identitiesRegisterCmd.Flags().BoolVar(&identityEnroll, "enroll", false, "Enroll the identity after registering it")
+
+	identitiesEnrollCmd.Flags().StringVar(&identitySecret, "secret", "", "Enrollment secret returned when the identity was registered")
+	identitiesEnrollCmd.Flags().StringArrayVar(&identityAttributes, "attribute", []string{}, "Name of an attribute that must be included in the enrollment certificate. Can be specified multiple times")
+
+	identitiesRevokeCmd.Flags().StringVar(&identityRevokeReason, "reason", "", "Reason for revoking the identity")
+
+	identitiesCmd.AddCommand(identitiesRegisterCmd)
+	identitiesCmd.AddCommand(identitiesEnrollCmd)
+	identitiesCmd.AddCommand(identitiesListCmd)
+	identitiesCmd.AddCommand(identitiesRevokeCmd)
+	rootCmd.AddCommand(identitiesCmd)
+}
@@ -0,0 +1,39 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIdentityAttributes(T *testing.T) {
+	attributes, err := parseIdentityAttributes([]string{"role=admin", "query=a=b", "empty="})
+	assert.NoError(T, err)
+	assert.Equal(T, map[string]string{"role": "admin", "query": "a=b", "empty": ""}, attributes)
+
+	attributes, err = parseIdentityAttributes([]string{})
+	assert.NoError(T, err)
+	assert.Empty(T, attributes)
+
+	_, err = parseIdentityAttributes([]string{"role"})
+	assert.EqualError(T, err, "invalid attribute 'role' - attributes must be in the format <name>=<value>")
+
+	_, err = parseIdentityAttributes([]string{"=admin"})
+	assert.Error(T, err)
+}
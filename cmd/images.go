@@ -0,0 +1,78 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Save and load the docker images used by a stack",
+	Long: `Save and load the docker images used by a stack
+
+The images can be saved to a tar file on a machine with access to the registries, and
+loaded on machines without internet access, such as CI runners. Stacks can be created
+on those machines with a manifest file, or with the release manifests cached from
+earlier runs of 'ff init'.`,
+}
+
+var imagesSaveCmd = &cobra.Command{
+	Use:   "save <stack_name> <tar_file>",
+	Short: "Save all of the docker images used by a stack to a tar file",
+	Long: `Save all of the docker images used by a stack to a tar file
+
+The images must already be on this machine - run 'ff pull' for the stack first.
+Docker doesn't keep the digests of the images it saves, so images that are pinned
+by digest in the stack's manifest are saved under a tag made from the digest, such
+as 'ghcr.io/hyperledger/firefly:sha256-<digest>'. Stacks created with 'ff init
+--saved-images' use these tags instead of the digests.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+		if err := stackManager.SaveImages(args[1], verbose); err != nil {
+			return err
+		}
+		fmt.Printf("saved the images for stack '%s' to %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var imagesLoadCmd = &cobra.Command{
+	Use:   "load <tar_file>",
+	Short: "Load docker images from a tar file saved with 'ff images save'",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := stacks.LoadImages(args[0], verbose); err != nil {
+			return err
+		}
+		fmt.Printf("loaded the images in %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesSaveCmd)
+	imagesCmd.AddCommand(imagesLoadCmd)
+	rootCmd.AddCommand(imagesCmd)
+}
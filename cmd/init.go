@@ -19,6 +19,8 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -26,17 +28,30 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric"
 	"github.com/hyperledger/firefly-cli/internal/constants"
 	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
 var initOptions stacks.InitOptions
 var databaseSelection string
 var blockchainProviderInput string
 var tokensProviderSelection string
+var sharedStorageSelection string
+var resourceProfileSelection string
+var dataExchangeSelection string
 var promptNames bool
+var prefundedAccounts []string
+var memberConfigs []string
 
 var ffNameValidator = regexp.MustCompile(`^[0-9a-zA-Z]([0-9a-zA-Z._-]{0,62}[0-9a-zA-Z])?$`)
+var ethAddressValidator = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+var balanceValidator = regexp.MustCompile(`^(0x[0-9a-fA-F]+|[0-9]+)$`)
+var fabricChannelValidator = regexp.MustCompile(`^[a-z][a-z0-9.-]{0,248}$`)
+var dockerNetworkValidator = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+var fabricChaincodeValidator = regexp.MustCompile(`^[a-zA-Z0-9]+([-_][a-zA-Z0-9]+)*$`)
 
 var initCmd = &cobra.Command{
 	Use:   "init [stack_name] [member_count]",
@@ -50,12 +65,44 @@ var initCmd = &cobra.Command{
 		if err := validateDatabaseProvider(databaseSelection); err != nil {
 			return err
 		}
+		if err := validateSharedDatabase(initOptions.SharedDatabase, databaseSelection); err != nil {
+			return err
+		}
 		if err := validateBlockchainProvider(blockchainProviderInput); err != nil {
 			return err
 		}
 		if err := validateTokensProvider(tokensProviderSelection); err != nil {
 			return err
 		}
+		if err := validateSharedStorageProvider(sharedStorageSelection); err != nil {
+			return err
+		}
+		if err := validateDataExchangeProvider(dataExchangeSelection); err != nil {
+			return err
+		}
+		if err := validateResourceProfile(resourceProfileSelection); err != nil {
+			return err
+		}
+		if err := validateNetwork(initOptions.Network); err != nil {
+			return err
+		}
+		if err := parsePrefundedAccounts(prefundedAccounts); err != nil {
+			return err
+		}
+		memberConfigPaths, err := parseMemberConfigs(memberConfigs)
+		if err != nil {
+			return err
+		}
+		initOptions.MemberConfigPaths = memberConfigPaths
+		if err := validateHost(initOptions.Host); err != nil {
+			return err
+		}
+		if initOptions.Mnemonic != "" {
+			initOptions.Mnemonic = strings.Join(strings.Fields(initOptions.Mnemonic), " ")
+			if err := stacks.ValidateMnemonic(initOptions.Mnemonic); err != nil {
+				return err
+			}
+		}
 
 		fmt.Println("initializing new FireFly stack...")
 
@@ -81,26 +128,15 @@ var initCmd = &cobra.Command{
 		}
 		memberCount, _ := strconv.Atoi(memberCountInput)
 
-		initOptions.OrgNames = make([]string, 0, memberCount)
-		initOptions.NodeNames = make([]string, 0, memberCount)
-		if promptNames {
-			for i := 0; i < memberCount; i++ {
-				name, _ := prompt(fmt.Sprintf("name for org %d: ", i), validateFFName)
-				initOptions.OrgNames = append(initOptions.OrgNames, name)
-				name, _ = prompt(fmt.Sprintf("name for node %d: ", i), validateFFName)
-				initOptions.NodeNames = append(initOptions.NodeNames, name)
-			}
-		} else {
-			for i := 0; i < memberCount; i++ {
-				initOptions.OrgNames = append(initOptions.OrgNames, fmt.Sprintf("org_%d", i))
-				initOptions.NodeNames = append(initOptions.NodeNames, fmt.Sprintf("node_%d", i))
-			}
-		}
+		initOptions.OrgNames, initOptions.NodeNames = getMemberNames(memberCount, 0)
 
 		initOptions.Verbose = verbose
 		initOptions.BlockchainProvider, _ = stacks.BlockchainProviderFromString(blockchainProviderInput)
 		initOptions.DatabaseSelection, _ = stacks.DatabaseSelectionFromString(databaseSelection)
 		initOptions.TokensProvider, _ = stacks.TokensProviderFromString(tokensProviderSelection)
+		initOptions.SharedStorageProvider, _ = stacks.SharedStorageProviderFromString(sharedStorageSelection)
+		initOptions.DataExchangeProvider, _ = stacks.DataExchangeProviderFromString(dataExchangeSelection)
+		initOptions.ResourceProfile, _ = stacks.ResourceProfileFromString(resourceProfileSelection)
 
 		if err := stackManager.InitStack(stackName, memberCount, &initOptions); err != nil {
 			return err
@@ -112,6 +148,27 @@ var initCmd = &cobra.Command{
 	},
 }
 
+// getMemberNames returns the org and node names for each member. Default names are numbered from the offset,
+// so that they are unique across a network that already has that many members
+func getMemberNames(memberCount int, offset int) (orgNames []string, nodeNames []string) {
+	orgNames = make([]string, 0, memberCount)
+	nodeNames = make([]string, 0, memberCount)
+	if promptNames {
+		for i := 0; i < memberCount; i++ {
+			name, _ := prompt(fmt.Sprintf("name for org %d: ", i), validateFFName)
+			orgNames = append(orgNames, name)
+			name, _ = prompt(fmt.Sprintf("name for node %d: ", i), validateFFName)
+			nodeNames = append(nodeNames, name)
+		}
+	} else {
+		for i := 0; i < memberCount; i++ {
+			orgNames = append(orgNames, fmt.Sprintf("org_%d", i+offset))
+			nodeNames = append(nodeNames, fmt.Sprintf("node_%d", i+offset))
+		}
+	}
+	return orgNames, nodeNames
+}
+
 func validateStackName(stackName string) error {
 	if strings.TrimSpace(stackName) == "" {
 		return errors.New("stack name must not be empty")
@@ -141,6 +198,28 @@ func validateFFName(input string) error {
 	return nil
 }
 
+func validateHost(input string) error {
+	if input == "" {
+		return nil
+	}
+	if strings.Contains(input, "://") || strings.ContainsAny(input, " /:") {
+		return fmt.Errorf("'%s' is not a valid host - please provide a hostname or IP address, without a scheme or port", input)
+	}
+	return nil
+}
+
+// validateNetwork checks the name of an external docker network. The stack's own network is called default
+// in the compose file, so an external network can't use that name
+func validateNetwork(input string) error {
+	if input == "" {
+		return nil
+	}
+	if !dockerNetworkValidator.MatchString(input) || input == "default" {
+		return fmt.Errorf("'%s' is not a valid docker network name", input)
+	}
+	return nil
+}
+
 func validateDatabaseProvider(input string) error {
 	_, err := stacks.DatabaseSelectionFromString(input)
 	if err != nil {
@@ -149,6 +228,15 @@ func validateDatabaseProvider(input string) error {
 	return nil
 }
 
+func validateSharedDatabase(sharedDatabase bool, databaseInput string) error {
+	if sharedDatabase {
+		if databaseSelection, _ := stacks.DatabaseSelectionFromString(databaseInput); databaseSelection != stacks.PostgreSQL {
+			return fmt.Errorf("--shared-database is only supported with the '%s' database", stacks.PostgreSQL)
+		}
+	}
+	return nil
+}
+
 func validateBlockchainProvider(input string) error {
 	blockchainSelection, err := stacks.BlockchainProviderFromString(input)
 	if err != nil {
@@ -159,14 +247,198 @@ func validateBlockchainProvider(input string) error {
 		return errors.New("support for corda is coming soon")
 	}
 
+	if err := validateGenesisOptions(blockchainSelection); err != nil {
+		return err
+	}
+
 	// TODO: When we get tokens on Fabric this should change
-	if blockchainSelection == stacks.HyperledgerFabric {
+	if blockchainSelection == stacks.HyperledgerFabric || blockchainSelection == stacks.FabricRemote {
 		tokensProviderSelection = "none"
+		if len(initOptions.Contracts) > 0 {
+			return errors.New("the --contract flag is only supported with ethereum based blockchain providers")
+		}
+		if blockchainSelection == stacks.FabricRemote {
+			if err := validateRemoteFabricOptions(); err != nil {
+				return err
+			}
+		} else if initOptions.ConnectionProfile != "" || initOptions.CryptoDir != "" {
+			return errors.New("the --ccp and --fabric-crypto-dir flags are only supported with the fabric-remote blockchain provider")
+		}
+		if initOptions.RPCURL != "" || initOptions.FundingKey != "" {
+			return errors.New("the --rpc-url and --funding-key flags are only supported with the ethereum-remote blockchain provider")
+		}
+		return validateFabricOptions()
+	}
+
+	if len(initOptions.Channels) > 0 || initOptions.ChaincodeName != "firefly" || initOptions.EndorsementPolicy != "" || initOptions.Orderers != 1 || initOptions.Configtx != "" || initOptions.GenesisProfile != "" {
+		return errors.New("the --channel, --chaincode-name, --endorsement-policy, --orderers, --configtx and --genesis-profile flags are only supported with the fabric blockchain provider")
+	}
+	if initOptions.ConnectionProfile != "" || initOptions.CryptoDir != "" {
+		return errors.New("the --ccp and --fabric-crypto-dir flags are only supported with the fabric-remote blockchain provider")
+	}
+
+	// Other providers start a new chain, where no contract can exist yet. Stacks that join a network get the
+	// contract address from the join token
+	if initOptions.FireFlyContract != "" && blockchainSelection != stacks.EthereumRemote {
+		return errors.New("the --firefly-contract-address flag is only supported with the ethereum-remote blockchain provider")
+	}
+	if initOptions.FireFlyContract != "" && !ethAddressValidator.MatchString(initOptions.FireFlyContract) {
+		return fmt.Errorf("'%s' is not a valid ethereum address", initOptions.FireFlyContract)
+	}
+
+	if blockchainSelection == stacks.EthereumRemote {
+		return validateRemoteEthereumOptions()
+	}
+	if initOptions.RPCURL != "" || initOptions.FundingKey != "" {
+		return errors.New("the --rpc-url and --funding-key flags are only supported with the ethereum-remote blockchain provider")
+	}
+
+	return nil
+}
+
+func validateGenesisOptions(blockchainSelection stacks.BlockchainProvider) error {
+	// Only the geth provider creates its own chain, so the other providers can't use the genesis options
+	if blockchainSelection != stacks.GoEthereum && (initOptions.ChainID != types.DefaultChainID || initOptions.BlockPeriod != 0 || initOptions.GasLimit != 0 || initOptions.BerlinBlock >= 0 || initOptions.London || len(prefundedAccounts) > 0) {
+		return errors.New("the --chain-id, --block-period, --gas-limit, --berlin-block, --london and --prefund flags are only supported with the geth blockchain provider")
+	}
+	// London activates in the genesis block, and requires Berlin to be active
+	if initOptions.London && initOptions.BerlinBlock > 0 {
+		return errors.New("the London hard fork requires the Berlin hard fork to be active in the genesis block - please use --berlin-block 0")
+	}
+	return nil
+}
+
+func validateRemoteEthereumOptions() error {
+	if initOptions.RPCURL == "" {
+		return errors.New("the --rpc-url flag is required with the ethereum-remote blockchain provider")
+	}
+	if u, err := url.Parse(initOptions.RPCURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid JSON-RPC URL - it must be an http or https URL", initOptions.RPCURL)
+	}
+	if initOptions.FundingKey != "" {
+		if _, _, err := stacks.ParsePrivateKey(initOptions.FundingKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRemoteFabricOptions() (err error) {
+	if initOptions.ConnectionProfile == "" || initOptions.CryptoDir == "" {
+		return errors.New("the --ccp and --fabric-crypto-dir flags are required with the fabric-remote blockchain provider")
+	}
+	if initOptions.Orderers != 1 || initOptions.EndorsementPolicy != "" || initOptions.Configtx != "" || initOptions.GenesisProfile != "" {
+		return errors.New("the --orderers, --endorsement-policy, --configtx and --genesis-profile flags are not supported with the fabric-remote blockchain provider")
+	}
+	// Save absolute paths, as the crypto directory is mounted into the fabconnect containers
+	if initOptions.ConnectionProfile, err = filepath.Abs(initOptions.ConnectionProfile); err != nil {
+		return err
+	}
+	if initOptions.CryptoDir, err = filepath.Abs(initOptions.CryptoDir); err != nil {
+		return err
+	}
+	if _, err := os.Stat(initOptions.ConnectionProfile); err != nil {
+		return err
+	}
+	if info, err := os.Stat(initOptions.CryptoDir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", initOptions.CryptoDir)
+	}
+	return nil
+}
+
+func validateFabricOptions() error {
+	channels := make(map[string]bool, len(initOptions.Channels))
+	for _, channel := range initOptions.Channels {
+		if !fabricChannelValidator.MatchString(channel) {
+			return fmt.Errorf("'%s' is not a valid channel name - channel names must start with a lowercase letter, and contain only lowercase alphanumerics, dots (.) and dashes (-)", channel)
+		}
+		if channels[channel] {
+			return fmt.Errorf("channel '%s' was specified more than once", channel)
+		}
+		channels[channel] = true
+	}
+	if initOptions.Orderers < 1 {
+		return errors.New("a fabric network must have at least one orderer")
+	}
+	// An etcdraft cluster needs a majority of its orderers running, so an even number tolerates no more
+	// orderers being stopped than the odd number below it
+	if initOptions.Orderers%2 == 0 {
+		return fmt.Errorf("an etcdraft cluster of %d orderers can only lose as many orderers as a cluster of %d - please use an odd number of orderers", initOptions.Orderers, initOptions.Orderers-1)
+	}
+	if !fabricChaincodeValidator.MatchString(initOptions.ChaincodeName) {
+		return fmt.Errorf("'%s' is not a valid chaincode name - chaincode names must contain only alphanumerics, dashes (-) and underscores (_)", initOptions.ChaincodeName)
+	}
+	if initOptions.EndorsementPolicy != "" {
+		if err := fabric.ValidateSignaturePolicy(initOptions.EndorsementPolicy); err != nil {
+			return err
+		}
+	}
+	return validateGenesisProfile()
+}
+
+func validateGenesisProfile() (err error) {
+	if initOptions.Configtx == "" {
+		if initOptions.GenesisProfile != "" && initOptions.GenesisProfile != types.DefaultGenesisProfile {
+			return fmt.Errorf("the generated configtx.yaml only contains the %s profile - please use --configtx to use another profile", types.DefaultGenesisProfile)
+		}
+		return nil
+	}
+	// Save an absolute path, as the file is copied into the stack whenever its config is written
+	if initOptions.Configtx, err = filepath.Abs(initOptions.Configtx); err != nil {
+		return err
+	}
+	profiles, err := fabric.GetConfigtxProfiles(initOptions.Configtx)
+	if err != nil {
+		return err
+	}
+	profile := initOptions.GenesisProfile
+	if profile == "" {
+		profile = types.DefaultGenesisProfile
 	}
+	for _, p := range profiles {
+		if p == profile {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' does not contain the %s profile - please use --genesis-profile to use one of: %s", initOptions.Configtx, profile, strings.Join(profiles, ", "))
+}
 
+func parsePrefundedAccounts(input []string) error {
+	initOptions.PrefundedAccounts = make(map[string]string, len(input))
+	for _, account := range input {
+		address, balance := account, ethereum.DefaultAccountBalance
+		if i := strings.Index(account, "="); i >= 0 {
+			address, balance = account[:i], account[i+1:]
+		}
+		if !ethAddressValidator.MatchString(address) {
+			return fmt.Errorf("'%s' is not a valid ethereum address", address)
+		}
+		if balance == "" {
+			return fmt.Errorf("no balance specified for account '%s'", address)
+		}
+		if !balanceValidator.MatchString(balance) {
+			return fmt.Errorf("'%s' is not a valid balance for account '%s' - please use a decimal or 0x prefixed hex number of wei", balance, address)
+		}
+		initOptions.PrefundedAccounts[strings.ToLower(address)] = balance
+	}
 	return nil
 }
 
+// Member configs are specified in the format <member>=<file>, where the member is an ID or org name
+func parseMemberConfigs(input []string) (map[string]string, error) {
+	memberConfigPaths := make(map[string]string, len(input))
+	for _, memberConfig := range input {
+		i := strings.Index(memberConfig, "=")
+		if i <= 0 || i == len(memberConfig)-1 {
+			return nil, fmt.Errorf("'%s' is not a valid member config - please use the format <member>=<file>", memberConfig)
+		}
+		memberConfigPaths[memberConfig[:i]] = memberConfig[i+1:]
+	}
+	return memberConfigPaths, nil
+}
+
 func validateTokensProvider(input string) error {
 	_, err := stacks.TokensProviderFromString(input)
 	if err != nil {
@@ -175,16 +447,74 @@ func validateTokensProvider(input string) error {
 	return nil
 }
 
+func validateSharedStorageProvider(input string) error {
+	sharedStorageSelection, err := stacks.SharedStorageProviderFromString(input)
+	if err != nil {
+		return err
+	}
+	// Members on other machines peer with the IPFS node of each member
+	if initOptions.Host != "" && sharedStorageSelection != stacks.IPFS {
+		return fmt.Errorf("--host is only supported with the '%s' shared storage provider", stacks.IPFS)
+	}
+	return nil
+}
+
+func validateDataExchangeProvider(input string) error {
+	_, err := stacks.DataExchangeProviderFromString(input)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateResourceProfile(input string) error {
+	_, err := stacks.ResourceProfileFromString(input)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func init() {
 	initCmd.Flags().IntVarP(&initOptions.FireFlyBasePort, "firefly-base-port", "p", 5000, "Mapped port base of FireFly core API (1 added for each member)")
 	initCmd.Flags().IntVarP(&initOptions.ServicesBasePort, "services-base-port", "s", 5100, "Mapped port base of services (100 added for each member)")
 	initCmd.Flags().StringVarP(&databaseSelection, "database", "d", "sqlite3", fmt.Sprintf("Database type to use. Options are: %v", stacks.DBSelectionStrings))
+	initCmd.Flags().BoolVar(&initOptions.SharedDatabase, "shared-database", false, "Run a single PostgreSQL server with a database for each member, instead of a server for each member")
 	initCmd.Flags().StringVarP(&blockchainProviderInput, "blockchain-provider", "b", "geth", fmt.Sprintf("Blockchain provider to use. Options are: %v", stacks.BlockchainProviderStrings))
 	initCmd.Flags().StringVarP(&tokensProviderSelection, "tokens-provider", "t", "erc1155", fmt.Sprintf("Tokens provider to use. Options are: %v", stacks.TokensProviderStrings))
+	initCmd.Flags().StringVar(&sharedStorageSelection, "storage", "ipfs", fmt.Sprintf("Shared storage provider to use. Options are: %v. The s3 provider needs a FireFly core image with an s3 shared storage plugin, which releases up to v1.0 do not include", stacks.SharedStorageProviderStrings))
+	initCmd.Flags().StringVar(&dataExchangeSelection, "dataexchange", "https", fmt.Sprintf("Data exchange provider to use. Options are: %v", stacks.DataExchangeProviderStrings))
+	initCmd.Flags().StringVar(&resourceProfileSelection, "profile", "default", fmt.Sprintf("Resource profile setting the memory and CPU limits and restart policy of the stack's containers. Options are: %v", stacks.ResourceProfileStrings))
+	initCmd.Flags().StringVar(&initOptions.Network, "network", "", "Name of an existing docker network to connect every container in the stack to, in addition to the stack's own network")
+	initCmd.Flags().BoolVar(&initOptions.DataExchangePeers, "prepopulate-dx-peers", false, "Write every other member's data exchange endpoint and certificate into each member's data exchange config, so that members can message each other before their nodes are registered")
 	initCmd.Flags().IntVarP(&initOptions.ExternalProcesses, "external", "e", 0, "Manage a number of FireFly core processes outside of the docker-compose stack - useful for development and debugging")
 	initCmd.Flags().StringVarP(&initOptions.FireFlyVersion, "release", "r", "latest", "Select the FireFly release version to use")
 	initCmd.Flags().StringVarP(&initOptions.ManifestPath, "manifest", "m", "", "Path to a manifest.json file containing the versions of each FireFly microservice to use. Overrides the --release flag.")
 	initCmd.Flags().BoolVar(&promptNames, "prompt-names", false, "Prompt for org and node names instead of using the defaults")
+	initCmd.Flags().IntVar(&initOptions.ChainID, "chain-id", types.DefaultChainID, "Ethereum chain ID, also used as the network ID of the blockchain node")
+	initCmd.Flags().IntVar(&initOptions.BlockPeriod, "block-period", 0, "Ethereum block period in seconds. 0 only mines blocks when there are pending transactions")
+	initCmd.Flags().Uint64Var(&initOptions.GasLimit, "gas-limit", 0, "Ethereum block gas limit, used in the genesis block and as the miner's gas target. A development default is used if not set")
+	initCmd.Flags().IntVar(&initOptions.BerlinBlock, "berlin-block", -1, "Block number at which to activate the Ethereum Berlin hard fork (disabled if negative)")
+	initCmd.Flags().BoolVar(&initOptions.London, "london", false, "Activate the Ethereum London hard fork, including EIP-1559, in the genesis block with a base fee of 0. Ethconnect sends transactions with a gas price of 0, so London can't be activated in a later block, where the base fee starts at 1 gwei")
+	initCmd.Flags().StringArrayVar(&prefundedAccounts, "prefund", []string{}, "Ethereum account to fund in the genesis block, in the format <address>[=<balance>]. Can be specified multiple times")
+	initCmd.Flags().StringArrayVar(&initOptions.Contracts, "contract", []string{}, "Compiled contract JSON or Solidity source file to deploy when the stack is first started, in the format <path>[#<contract>][:<name>[:<arg>=<value>,...]], where <contract> selects the contract in a Solidity file. Can be specified multiple times")
+	initCmd.Flags().StringVar(&initOptions.SolcBasePath, "solc-base-path", "", "Directory that imports in Solidity source files passed to --contract are resolved within. Defaults to each source file's directory")
+	initCmd.Flags().StringArrayVar(&initOptions.Channels, "channel", []string{}, "Fabric channel to create and join. Can be specified multiple times - FireFly uses the first channel (default firefly)")
+	initCmd.Flags().StringVar(&initOptions.ChaincodeName, "chaincode-name", "firefly", "Name to commit the FireFly chaincode with on a Fabric channel")
+	initCmd.Flags().StringVar(&initOptions.EndorsementPolicy, "endorsement-policy", "", "Fabric signature policy for chaincode definitions, for example \"OR('Org1MSP.member')\". Uses the channel's default endorsement policy if not set")
+	initCmd.Flags().StringVar(&initOptions.Configtx, "configtx", "", "configtx.yaml file to generate the genesis block of each Fabric channel from, instead of the generated one. Organizations must use the crypto material that cryptogen writes to /etc/firefly/organizations")
+	initCmd.Flags().StringVar(&initOptions.GenesisProfile, "genesis-profile", "", fmt.Sprintf("Profile in configtx.yaml to generate the genesis block of each Fabric channel from (default %s)", types.DefaultGenesisProfile))
+	initCmd.Flags().IntVar(&initOptions.Orderers, "orderers", 1, "Number of orderers in the Fabric etcdraft ordering service")
+	initCmd.Flags().StringVar(&initOptions.RPCURL, "rpc-url", "", "JSON-RPC URL of an existing Ethereum node to connect to with the ethereum-remote blockchain provider. Must be reachable from inside the stack's containers, and from this machine with host.docker.internal replaced by localhost. Member keys are imported into the node and stay unlocked, so anyone with access to its RPC API can use the members' accounts")
+	initCmd.Flags().StringVar(&initOptions.FundingKey, "funding-key", "", "Private key of an account on the remote Ethereum chain, used to fund member accounts with the ethereum-remote blockchain provider. The key is only used during init and is not saved")
+	initCmd.Flags().StringVar(&initOptions.FireFlyContract, "firefly-contract-address", "", "Address of an existing FireFly contract to register with every member, instead of deploying a new one, with the ethereum-remote blockchain provider. Allows stacks to join an existing FireFly network on the same chain")
+	initCmd.Flags().StringVar(&initOptions.ConnectionProfile, "ccp", "", "Path to the connection profile of an existing Fabric network to connect to with the fabric-remote blockchain provider")
+	initCmd.Flags().StringVar(&initOptions.CryptoDir, "fabric-crypto-dir", "", "Directory containing the crypto material referenced by the connection profile, mounted at /etc/firefly in each fabconnect container")
+	initCmd.Flags().StringVar(&initOptions.Host, "host", "", "Hostname or IP address that machines on the network can reach this stack at. Exposes the ports needed for stacks on other machines to join this stack's network")
+	initCmd.Flags().StringVar(&initOptions.FireFlyConfigPath, "firefly-config", "", "YAML file of FireFly core config to deep merge into the config generated for every member")
+	initCmd.Flags().StringArrayVar(&memberConfigs, "member-config", []string{}, "YAML file of FireFly core config to deep merge into one member's config, in the format <member>=<file>. Applied after --firefly-config. Can be specified multiple times")
+	initCmd.Flags().BoolVar(&initOptions.UseSavedImages, "saved-images", false, "Use the tags that images pinned by digest are saved under by 'ff images save', for stacks started from images loaded with 'ff images load'")
+	initCmd.Flags().StringVar(&initOptions.Mnemonic, "mnemonic", "", "BIP-39 mnemonic used to derive member keys. A new mnemonic is generated and saved to the stack if not set.")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -0,0 +1,144 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+)
+
+var joinOptions stacks.InitOptions
+var joinDatabaseSelection string
+var joinTokensProviderSelection string
+var joinResourceProfileSelection string
+
+var joinCmd = &cobra.Command{
+	Use:   "join <stack_name> <member_count> <join_token>",
+	Short: "Create a new stack that joins the network of a stack on another machine",
+	Long: `Create a new stack that joins the network of a stack on another machine
+
+The join token is printed by running 'ff join-token' for the stack on the other machine,
+and can be passed directly or as the path to a file containing the token. The new stack
+runs its own blockchain node, peered with the nodes in the network, and uses the network's
+FireFly and ERC1155 token contracts. Once the new stack has been started, accept its own
+join token with 'ff join-accept' on the other machines in the network.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager := stacks.NewStackManager(logger)
+
+		stackName := args[0]
+		if err := validateStackName(stackName); err != nil {
+			return err
+		}
+		if err := validateCount(args[1]); err != nil {
+			return err
+		}
+		memberCount, _ := strconv.Atoi(args[1])
+		if joinOptions.ExternalProcesses >= memberCount {
+			return errors.New("number of external processes should not be equal to or greater than the number of members in the stack")
+		}
+		if joinOptions.Host == "" {
+			return errors.New("the --host flag is required, so that members on other machines can reach this stack")
+		}
+		if err := validateHost(joinOptions.Host); err != nil {
+			return err
+		}
+		if err := validateDatabaseProvider(joinDatabaseSelection); err != nil {
+			return err
+		}
+		if err := validateSharedDatabase(joinOptions.SharedDatabase, joinDatabaseSelection); err != nil {
+			return err
+		}
+		if err := validateTokensProvider(joinTokensProviderSelection); err != nil {
+			return err
+		}
+		if err := validateResourceProfile(joinResourceProfileSelection); err != nil {
+			return err
+		}
+		if err := validateNetwork(joinOptions.Network); err != nil {
+			return err
+		}
+
+		encodedToken := args[2]
+		if _, err := os.Stat(encodedToken); err == nil {
+			tokenBytes, err := ioutil.ReadFile(encodedToken)
+			if err != nil {
+				return err
+			}
+			encodedToken = string(tokenBytes)
+		}
+		token, err := stacks.DecodeJoinToken(encodedToken)
+		if err != nil {
+			return err
+		}
+
+		// Number the default names after the members already in the network, as org names must be unique
+		joinOptions.OrgNames, joinOptions.NodeNames = getMemberNames(memberCount, len(token.Members))
+		for _, member := range token.Members {
+			for _, orgName := range joinOptions.OrgNames {
+				if orgName == member.OrgName {
+					return fmt.Errorf("org '%s' is already a member of the network", orgName)
+				}
+			}
+		}
+
+		joinOptions.Verbose = verbose
+		joinOptions.JoinToken = token
+		joinOptions.BlockchainProvider = stacks.GoEthereum
+		joinOptions.DatabaseSelection, _ = stacks.DatabaseSelectionFromString(joinDatabaseSelection)
+		joinOptions.TokensProvider, _ = stacks.TokensProviderFromString(joinTokensProviderSelection)
+		if joinOptions.TokensProvider == stacks.ERC1155 && token.TokenContractAddress == "" {
+			return errors.New("the network does not have an ERC1155 token contract - use '--tokens-provider none' to join it")
+		}
+		joinOptions.ResourceProfile, _ = stacks.ResourceProfileFromString(joinResourceProfileSelection)
+		joinOptions.ChaincodeName = "firefly"
+		joinOptions.Orderers = 1
+		joinOptions.BerlinBlock = -1
+
+		if err := stackManager.InitStack(stackName, memberCount, &joinOptions); err != nil {
+			return err
+		}
+
+		fmt.Printf("Stack '%s' created, joining a network with %d other member(s)!\nTo start your new stack run:\n\n%s start %s\n", stackName, len(token.Members), rootCmd.Use, stackName)
+		fmt.Printf("\nYour docker compose file for this stack can be found at: %s\n\n", filepath.Join(constants.StacksDir, stackName, "docker-compose.yml"))
+		return nil
+	},
+}
+
+func init() {
+	joinCmd.Flags().StringVar(&joinOptions.Host, "host", "", "Hostname or IP address that machines on the network can reach this stack at")
+	joinCmd.Flags().IntVarP(&joinOptions.FireFlyBasePort, "firefly-base-port", "p", 5000, "Mapped port base of FireFly core API (1 added for each member)")
+	joinCmd.Flags().IntVarP(&joinOptions.ServicesBasePort, "services-base-port", "s", 5100, "Mapped port base of services (100 added for each member)")
+	joinCmd.Flags().StringVarP(&joinDatabaseSelection, "database", "d", "sqlite3", fmt.Sprintf("Database type to use. Options are: %v", stacks.DBSelectionStrings))
+	joinCmd.Flags().BoolVar(&joinOptions.SharedDatabase, "shared-database", false, "Run a single PostgreSQL server with a database for each member, instead of a server for each member")
+	joinCmd.Flags().StringVarP(&joinTokensProviderSelection, "tokens-provider", "t", "erc1155", fmt.Sprintf("Tokens provider to use. Options are: %v", stacks.TokensProviderStrings))
+	joinCmd.Flags().StringVar(&joinResourceProfileSelection, "profile", "default", fmt.Sprintf("Resource profile setting the memory and CPU limits and restart policy of the stack's containers. Options are: %v", stacks.ResourceProfileStrings))
+	joinCmd.Flags().StringVar(&joinOptions.Network, "network", "", "Name of an existing docker network to connect every container in the stack to, in addition to the stack's own network")
+	joinCmd.Flags().IntVarP(&joinOptions.ExternalProcesses, "external", "e", 0, "Manage a number of FireFly core processes outside of the docker-compose stack - useful for development and debugging")
+	joinCmd.Flags().BoolVar(&joinOptions.DataExchangePeers, "prepopulate-dx-peers", false, "Write the data exchange endpoint and certificate of every other member in the network into each member's data exchange config")
+	joinCmd.Flags().BoolVar(&promptNames, "prompt-names", false, "Prompt for org and node names instead of using the defaults")
+	rootCmd.AddCommand(joinCmd)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var joinAcceptCmd = &cobra.Command{
+	Use:   "join-accept <stack_name> <join_token>",
+	Short: "Record the members of a stack on another machine that joined a stack's network",
+	Long: `Record the members of a stack on another machine that joined a stack's network
+
+The join token is printed by running 'ff join-token' for the stack that joined, after it
+has been started, and can be passed directly or as the path to a file containing the token.
+The stack's geth and IPFS nodes peer with the nodes of the new members the next time the
+stack starts.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+
+		encodedToken := args[1]
+		if _, err := os.Stat(encodedToken); err == nil {
+			tokenBytes, err := ioutil.ReadFile(encodedToken)
+			if err != nil {
+				return err
+			}
+			encodedToken = string(tokenBytes)
+		}
+		token, err := stacks.DecodeJoinToken(encodedToken)
+		if err != nil {
+			return err
+		}
+
+		added, err := stackManager.AcceptJoinToken(token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d new member(s) added to stack '%s'. Restart the stack to peer with them:\n\n%s stop %s && %s start %s\n", added, args[0], rootCmd.Use, args[0], rootCmd.Use, args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(joinAcceptCmd)
+}
@@ -0,0 +1,63 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+var joinTokenOutput string
+
+var joinTokenCmd = &cobra.Command{
+	Use:   "join-token <stack_name>",
+	Short: "Print a token that stacks on other machines can use to join a stack's network",
+	Long: `Print a token that stacks on other machines can use to join a stack's network
+
+The stack must have been created with the --host flag, and started at least once. The
+token contains the network's genesis block, swarm key, and FireFly and token contract
+addresses, and the endpoints and data exchange certs of each member. Pass it to 'ff join' on the other
+machine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackManager := stacks.NewStackManager(logger)
+		if err := stackManager.LoadStack(args[0], verbose); err != nil {
+			return err
+		}
+		token, err := stackManager.CreateJoinToken()
+		if err != nil {
+			return err
+		}
+		if joinTokenOutput != "" {
+			if err := ioutil.WriteFile(joinTokenOutput, []byte(token), 0600); err != nil {
+				return err
+			}
+			fmt.Printf("join token written to %s\n", joinTokenOutput)
+			return nil
+		}
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func init() {
+	joinTokenCmd.Flags().StringVarP(&joinTokenOutput, "output", "o", "", "Write the token to a file instead of printing it")
+	rootCmd.AddCommand(joinTokenCmd)
+}
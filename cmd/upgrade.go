@@ -18,8 +18,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/hyperledger/firefly-cli/internal/core"
 	"github.com/hyperledger/firefly-cli/internal/stacks"
+	"github.com/hyperledger/firefly-cli/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +34,18 @@ var upgradeCmd = &cobra.Command{
 	Long: `Upgrade a stack by pulling newer images.
 	This operation will restart the stack if running.
 	If certain containers were pinned to a specific image at init,
-	this command will have no effect on those containers.`,
+	this command will have no effect on those containers.
+
+	With --release or --manifest, the stack is switched to the images in the
+	manifest of that FireFly release or in the manifest file. The images that
+	change are listed, and the stack's docker-compose.yml is written again to
+	use the new images. The stack is started again if it was running. Use
+	--dry-run to list the changes without upgrading the stack.
+
+	With --contracts, the stack is started after upgrading, and the FireFly
+	contract or chaincode from the new FireFly image is deployed. Ethereum
+	members are migrated to the newly registered contract instance, and the
+	FireFly chaincode is committed on the channel with the next sequence number.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		stackManager := stacks.NewStackManager(logger)
 		if len(args) == 0 {
@@ -45,15 +61,92 @@ var upgradeCmd = &cobra.Command{
 		if err := stackManager.LoadStack(stackName, verbose); err != nil {
 			return err
 		}
+
+		var manifest *types.VersionManifest
+		var err error
+		if upgradeManifestPath != "" {
+			manifest, err = core.ReadManifestFile(upgradeManifestPath)
+		} else if upgradeRelease != "" {
+			if strings.ToLower(upgradeRelease) == "latest" {
+				manifest, err = core.GetLatestReleaseManifest(logger)
+			} else {
+				manifest, err = core.GetReleaseManifest(logger, upgradeRelease)
+			}
+		} else if upgradeDryRun {
+			return fmt.Errorf("--dry-run requires --release or --manifest")
+		}
+		if err != nil {
+			return err
+		}
+
+		wasRunning := false
+		if manifest != nil {
+			changes := core.DiffManifests(stackManager.Stack.VersionManifest, manifest)
+			if len(changes) == 0 {
+				fmt.Printf("stack '%s' is already using the images in the manifest\n", stackName)
+				return nil
+			}
+			printManifestChanges(changes)
+			if upgradeDryRun {
+				return nil
+			}
+			if wasRunning, err = stackManager.IsRunning(verbose); err != nil {
+				return err
+			}
+			if err := stackManager.UpgradeVersionManifest(manifest); err != nil {
+				return err
+			}
+		}
+
 		fmt.Printf("upgrading stack '%s'... ", stackName)
 		if err := stackManager.UpgradeStack(verbose); err != nil {
 			return err
 		}
-		fmt.Printf("done\n\nYour stack has been upgraded. To start your upgraded stack run:\n\n%s start %s\n\n", rootCmd.Use, stackName)
+		fmt.Printf("done\n")
+		if upgradeContracts {
+			if err := stackManager.UpgradeContracts(verbose); err != nil {
+				return err
+			}
+			fmt.Printf("\nYour stack has been upgraded and is running with the new FireFly contracts\n\n")
+			return nil
+		}
+		if wasRunning {
+			if err := stackManager.StartStack(verbose, &stacks.StartOptions{}); err != nil {
+				return err
+			}
+			fmt.Printf("\n\nYour stack has been upgraded and restarted\n\n")
+			return nil
+		}
+		fmt.Printf("\nYour stack has been upgraded. To start your upgraded stack run:\n\n%s start %s\n\n", rootCmd.Use, stackName)
 		return nil
 	},
 }
 
+func printManifestChanges(changes []*core.ManifestChange) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tCURRENT IMAGE\tNEW IMAGE")
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", change.Component, imageOrNone(change.CurrentImage), imageOrNone(change.NewImage))
+	}
+	w.Flush()
+}
+
+func imageOrNone(image string) string {
+	if image == "" {
+		return "(none)"
+	}
+	return image
+}
+
+var upgradeContracts bool
+var upgradeRelease string
+var upgradeManifestPath string
+var upgradeDryRun bool
+
 func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeContracts, "contracts", false, "Start the stack after upgrading, and deploy the FireFly contract or chaincode from the new FireFly image")
+	upgradeCmd.Flags().StringVarP(&upgradeRelease, "release", "r", "", "Upgrade the stack to the images of a FireFly release version, or \"latest\"")
+	upgradeCmd.Flags().StringVarP(&upgradeManifestPath, "manifest", "m", "", "Path to a manifest.json file containing the versions of each FireFly microservice to upgrade to. Overrides the --release flag.")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "List the images that would change, without upgrading the stack")
 	rootCmd.AddCommand(upgradeCmd)
 }
@@ -26,6 +26,8 @@ type IBlockchainProvider interface {
 	WriteConfig() error
 	FirstTimeSetup() error
 	DeploySmartContracts() error
+	UpgradeSmartContracts() error
+	DeployContract(filename string, options *types.DeployOptions) (string, error)
 	PreStart() error
 	PostStart() error
 	GetDockerServiceDefinitions() []*docker.ServiceDefinition
@@ -39,7 +39,18 @@ func (p *BesuProvider) FirstTimeSetup() error {
 }
 
 func (p *BesuProvider) DeploySmartContracts() error {
-	return ethereum.DeployContracts(p.Stack, p.Log, p.Verbose)
+	if err := ethereum.DeployContracts(p.Stack, p.Log, p.Verbose); err != nil {
+		return err
+	}
+	return ethereum.DeployCustomContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *BesuProvider) UpgradeSmartContracts() error {
+	return ethereum.UpgradeContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *BesuProvider) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	return ethereum.DeployContractFile(p.Stack, p.Log, filename, options.ContractName, options.Name, options.Args, options.SolcBasePath, p.Verbose)
 }
 
 func (p *BesuProvider) PreStart() error {
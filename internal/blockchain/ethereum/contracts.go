@@ -21,7 +21,10 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/ethconnect"
 	"github.com/hyperledger/firefly-cli/internal/constants"
@@ -30,7 +33,77 @@ import (
 	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
+type ABIParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type ABIEntry struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name,omitempty"`
+	Inputs []*ABIParam `json:"inputs,omitempty"`
+}
+
+// Directory within the stack where contracts provided with 'ff init --contract' are kept
+const CustomContractsDir = "custom_contracts"
+
+// DeployContracts deploys the FireFly contract, unless the stack was created with the address of an existing
+// FireFly contract, in which case that contract is registered with every member's ethconnect instead
 func DeployContracts(s *types.Stack, log log.Logger, verbose bool) error {
+	name := GetFireFlyContractName(s)
+	if address := s.Ethereum.GetFireFlyContractAddress(); address != "" {
+		return registerFireFlyContract(s, log, verbose, name, address)
+	}
+	return deployFireFlyContract(s, log, verbose, name)
+}
+
+// UpgradeContracts deploys the FireFly contract from the currently running FireFly image under
+// a new registered name, so that members can be migrated to it without losing the old instance
+func UpgradeContracts(s *types.Stack, log log.Logger, verbose bool) error {
+	if s.Ethereum.GetFireFlyContractAddress() != "" {
+		return errors.New("this stack uses an existing FireFly contract that is shared with other stacks, so it cannot be upgraded from here")
+	}
+	return deployFireFlyContract(s, log, verbose, getNextFireFlyContractName(s))
+}
+
+func deployFireFlyContract(s *types.Stack, log log.Logger, verbose bool, name string) error {
+	fireflyContract, err := extractFireFlyContract(s, log, verbose)
+	if err != nil {
+		return err
+	}
+	contractAddress, err := DeployContractToStack(s, log, fireflyContract, name, map[string]string{})
+	if err != nil {
+		return err
+	}
+	s.FireFlyContract = &types.ContractDeployment{
+		Name:     name,
+		Filename: "Firefly.json",
+		Address:  contractAddress,
+	}
+	return nil
+}
+
+func registerFireFlyContract(s *types.Stack, log log.Logger, verbose bool, name string, contractAddress string) error {
+	fireflyContract, err := extractFireFlyContract(s, log, verbose)
+	if err != nil {
+		return err
+	}
+	for _, member := range s.Members {
+		log.Info(fmt.Sprintf("registering %s contract at %s on '%s'", name, contractAddress, member.ID))
+		if err := RegisterContract(member, fireflyContract, contractAddress, name, map[string]string{}); err != nil {
+			return err
+		}
+	}
+	s.FireFlyContract = &types.ContractDeployment{
+		Name:     name,
+		Filename: "Firefly.json",
+		Address:  contractAddress,
+	}
+	return nil
+}
+
+// extractFireFlyContract copies the compiled contracts out of the first FireFly core container in the stack
+func extractFireFlyContract(s *types.Stack, log log.Logger, verbose bool) (*types.Contract, error) {
 	var containerName string
 	for _, member := range s.Members {
 		if !member.External {
@@ -39,40 +112,219 @@ func DeployContracts(s *types.Stack, log log.Logger, verbose bool) error {
 		}
 	}
 	if containerName == "" {
-		return errors.New("unable to extract contracts from container - no valid firefly core containers found in stack")
+		return nil, errors.New("unable to extract contracts from container - no valid firefly core containers found in stack")
 	}
 	log.Info("extracting smart contracts")
 
 	if err := ExtractContracts(s.Name, containerName, "/firefly/contracts", verbose); err != nil {
-		return err
+		return nil, err
 	}
 
-	fireflyContract, err := ReadCompiledContract(filepath.Join(constants.StacksDir, s.Name, "contracts", "Firefly.json"))
-	if err != nil {
-		return err
+	return ReadCompiledContract(filepath.Join(constants.StacksDir, s.Name, "contracts", "Firefly.json"))
+}
+
+// NewFireFlyContractDeployment returns the FireFly contract for a new stack, which is registered under
+// a name that includes the FireFly version
+func NewFireFlyContractDeployment(s *types.Stack) *types.ContractDeployment {
+	return &types.ContractDeployment{
+		Name:     GetVersionedContractName("firefly", s.VersionManifest.FireFly),
+		Filename: "Firefly.json",
+	}
+}
+
+// GetVersionedContractName appends the image tag from the version manifest to a contract name,
+// so that contracts from different FireFly releases are registered side by side in ethconnect
+func GetVersionedContractName(name string, entry *types.ManifestEntry) string {
+	if entry == nil || entry.Tag == "" || entry.Tag == "latest" {
+		return name
+	}
+	tag := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(entry.Tag))
+	return name + "_" + tag
+}
+
+// GetFireFlyContractName returns the name the FireFly contract is registered under in ethconnect, which is
+// saved when the stack is created. Stacks created before contract names were recorded always used "firefly"
+func GetFireFlyContractName(s *types.Stack) string {
+	if s.FireFlyContract != nil && s.FireFlyContract.Name != "" {
+		return s.FireFlyContract.Name
+	}
+	return "firefly"
+}
+
+// Registered names cannot be reused, so if the FireFly version has not changed since the current
+// contract was deployed, a numeric suffix is added to the name (i.e. firefly_v0_11_4_2)
+func getNextFireFlyContractName(s *types.Stack) string {
+	name := GetVersionedContractName("firefly", s.VersionManifest.FireFly)
+	currentName := GetFireFlyContractName(s)
+	if currentName == name {
+		return name + "_2"
+	}
+	if strings.HasPrefix(currentName, name+"_") {
+		if n, err := strconv.Atoi(strings.TrimPrefix(currentName, name+"_")); err == nil {
+			return fmt.Sprintf("%s_%d", name, n+1)
+		}
+	}
+	return name
+}
+
+func DeployCustomContracts(s *types.Stack, log log.Logger, verbose bool) error {
+	for _, deployment := range s.Contracts {
+		contract, err := ReadCompiledContract(filepath.Join(constants.StacksDir, s.Name, CustomContractsDir, deployment.Filename))
+		if err != nil {
+			return fmt.Errorf("failed to read contract '%s': %s", deployment.Filename, err)
+		}
+		if deployment.Address, err = DeployContractToStack(s, log, contract, deployment.Name, deployment.Args); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	var fireflyContractAddress string
+// DeployContractToStack deploys a contract using the first member's ethconnect, and
+// registers the deployed contract under the same name with every other member's ethconnect
+func DeployContractToStack(s *types.Stack, log log.Logger, contract *types.Contract, name string, args map[string]string) (string, error) {
+	var contractAddress string
+	var err error
 	for _, member := range s.Members {
-		if fireflyContractAddress == "" {
-			// TODO: version the registered name
-			log.Info(fmt.Sprintf("deploying firefly contract on '%s'", member.ID))
-			fireflyContractAddress, err = DeployContract(member, fireflyContract, "firefly", map[string]string{})
+		if contractAddress == "" {
+			log.Info(fmt.Sprintf("deploying %s contract on '%s'", name, member.ID))
+			contractAddress, err = DeployContract(member, contract, name, args)
 			if err != nil {
-				return err
+				return "", err
 			}
 		} else {
-			log.Info(fmt.Sprintf("registering firefly contract on '%s'", member.ID))
-			err = RegisterContract(member, fireflyContract, fireflyContractAddress, "firefly", map[string]string{})
+			log.Info(fmt.Sprintf("registering %s contract on '%s'", name, member.ID))
+			err = RegisterContract(member, contract, contractAddress, name, args)
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 	}
+	return contractAddress, nil
+}
+
+// DeployContractFile deploys a compiled contract or Solidity source file to a running stack, mapping
+// the positional args to the names of the contract's constructor parameters, and returns the contract address.
+// contractName selects the contract in a Solidity file, and name is the name it is registered with
+func DeployContractFile(s *types.Stack, log log.Logger, filename string, contractName string, name string, args []string, basePath string, verbose bool) (string, error) {
+	if strings.ToLower(filepath.Ext(filename)) == ".sol" {
+		log.Info(fmt.Sprintf("compiling '%s'", filename))
+	}
+	contract, err := ReadContract(filename, contractName, basePath, verbose)
+	if err != nil {
+		return "", fmt.Errorf("failed to read contract '%s': %s", filename, err)
+	}
+	if contract.Bytecode == "" {
+		return "", fmt.Errorf("contract '%s' does not contain any bytecode", filename)
+	}
+	if name == "" {
+		name = GetDefaultContractName(contract, filename)
+	}
+	params, err := getConstructorParams(contract, args)
+	if err != nil {
+		return "", err
+	}
+	address, err := DeployContractToStack(s, log, contract, name, params)
+	if err != nil {
+		return "", err
+	}
+	// Save the deployment with the stack, so it is listed by 'ff info' and deployed again if the stack is reset
+	if err := saveCustomContract(s, contract, name, params, address); err != nil {
+		return "", err
+	}
+	return address, nil
+}
 
+func saveCustomContract(s *types.Stack, contract *types.Contract, name string, params map[string]string, address string) error {
+	contractsDir := filepath.Join(constants.StacksDir, s.Name, CustomContractsDir)
+	if err := os.MkdirAll(contractsDir, 0755); err != nil {
+		return err
+	}
+	filename := name + ".json"
+	for i := 2; isCustomContractFile(s, filename); i++ {
+		filename = fmt.Sprintf("%s_%d.json", name, i)
+	}
+	contractBytes, err := json.MarshalIndent(contract, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(contractsDir, filename), contractBytes, 0755); err != nil {
+		return err
+	}
+	s.Contracts = append(s.Contracts, &types.ContractDeployment{
+		Name:     name,
+		Filename: filename,
+		Args:     params,
+		Address:  address,
+	})
 	return nil
 }
 
+// Files are compared without case, as contracts that only differ by case would overwrite each other on some platforms
+func isCustomContractFile(s *types.Stack, filename string) bool {
+	for _, deployment := range s.Contracts {
+		if strings.EqualFold(deployment.Filename, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func GetDefaultContractName(contract *types.Contract, filename string) string {
+	if contract.ContractName != "" {
+		return strings.ToLower(contract.ContractName)
+	}
+	base := filepath.Base(filename)
+	return strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+func getConstructorParams(contract *types.Contract, args []string) (map[string]string, error) {
+	abiBytes, err := json.Marshal(contract.ABI)
+	if err != nil {
+		return nil, err
+	}
+	var abi []*ABIEntry
+	if err := json.Unmarshal(abiBytes, &abi); err != nil {
+		return nil, err
+	}
+	var inputs []*ABIParam
+	for _, entry := range abi {
+		if entry.Type == "constructor" {
+			inputs = entry.Inputs
+			break
+		}
+	}
+	if len(inputs) != len(args) {
+		return nil, fmt.Errorf("the contract constructor expects %d argument(s) but %d were provided", len(inputs), len(args))
+	}
+	params := make(map[string]string, len(args))
+	for i, input := range inputs {
+		name := getParamName(input, i)
+		if _, ok := params[name]; ok {
+			return nil, fmt.Errorf("the contract constructor has more than one parameter named '%s'", name)
+		}
+		params[name] = args[i]
+	}
+	return params, nil
+}
+
+// getParamName returns the name ethconnect uses for a parameter, which for unnamed parameters is
+// "input" for the first parameter, and "input" followed by the index for the others
+func getParamName(param *ABIParam, index int) string {
+	if param.Name != "" {
+		return param.Name
+	}
+	if index == 0 {
+		return "input"
+	}
+	return fmt.Sprintf("input%d", index)
+}
+
 func ReadCompiledContract(filePath string) (*types.Contract, error) {
 	d, _ := ioutil.ReadFile(filePath)
 	var contract *types.Contract
@@ -0,0 +1,93 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVersionedContractName(T *testing.T) {
+	assert.Equal(T, "firefly", GetVersionedContractName("firefly", nil))
+	assert.Equal(T, "firefly", GetVersionedContractName("firefly", &types.ManifestEntry{Tag: "latest"}))
+	assert.Equal(T, "firefly_v0_11_4", GetVersionedContractName("firefly", &types.ManifestEntry{Tag: "v0.11.4"}))
+	assert.Equal(T, "erc1155_v0_10_3_rc1", GetVersionedContractName("erc1155", &types.ManifestEntry{Tag: "v0.10.3-RC1"}))
+}
+
+func TestGetNextFireFlyContractName(T *testing.T) {
+	stack := &types.Stack{
+		VersionManifest: &types.VersionManifest{
+			FireFly: &types.ManifestEntry{Tag: "v0.11.4"},
+		},
+	}
+	assert.Equal(T, "firefly_v0_11_4", getNextFireFlyContractName(stack))
+
+	stack.FireFlyContract = &types.ContractDeployment{Name: "firefly_v0_11_4"}
+	assert.Equal(T, "firefly_v0_11_4_2", getNextFireFlyContractName(stack))
+
+	stack.FireFlyContract.Name = "firefly_v0_11_4_2"
+	assert.Equal(T, "firefly_v0_11_4_3", getNextFireFlyContractName(stack))
+
+	stack.VersionManifest.FireFly.Tag = "latest"
+	stack.FireFlyContract = nil
+	assert.Equal(T, "firefly_2", getNextFireFlyContractName(stack))
+}
+
+func TestGetFireFlyContractName(T *testing.T) {
+	stack := &types.Stack{
+		VersionManifest: &types.VersionManifest{
+			FireFly: &types.ManifestEntry{Tag: "v0.11.4"},
+		},
+	}
+	assert.Equal(T, "firefly", GetFireFlyContractName(stack))
+
+	stack.FireFlyContract = NewFireFlyContractDeployment(stack)
+	stack.VersionManifest.FireFly.Tag = "v0.12.0"
+	assert.Equal(T, "firefly_v0_11_4", GetFireFlyContractName(stack))
+}
+
+func TestUpgradeContractsWithExistingFireFlyContract(T *testing.T) {
+	stack := &types.Stack{
+		Ethereum: &types.EthereumOptions{
+			FireFlyContractAddress: "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+		},
+	}
+	assert.Regexp(T, "shared with other stacks", UpgradeContracts(stack, nil, false))
+}
+
+func TestGetConstructorParams(T *testing.T) {
+	contract := &types.Contract{
+		ABI: []interface{}{
+			map[string]interface{}{
+				"type": "constructor",
+				"inputs": []interface{}{
+					map[string]interface{}{"name": "", "type": "string"},
+					map[string]interface{}{"name": "symbol", "type": "string"},
+					map[string]interface{}{"name": "", "type": "uint256"},
+				},
+			},
+		},
+	}
+	params, err := getConstructorParams(contract, []string{"Coin", "CN", "100"})
+	assert.NoError(T, err)
+	assert.Equal(T, map[string]string{"input": "Coin", "symbol": "CN", "input2": "100"}, params)
+
+	_, err = getConstructorParams(contract, []string{"Coin"})
+	assert.Regexp(T, "expects 3 argument", err)
+}
@@ -23,7 +23,13 @@ import (
 	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
-func GetEthconnectServiceDefinitions(s *types.Stack) []*docker.ServiceDefinition {
+// GetEthconnectServiceDefinitions returns an ethconnect service for each member, connected to the
+// given JSON-RPC endpoint. If the blockchain node is part of the stack, ethconnect depends on its service
+func GetEthconnectServiceDefinitions(s *types.Stack, rpcUrl string, blockchainServiceName string) []*docker.ServiceDefinition {
+	var dependsOn map[string]map[string]string
+	if blockchainServiceName != "" {
+		dependsOn = map[string]map[string]string{blockchainServiceName: {"condition": "service_started"}}
+	}
 	serviceDefinitions := make([]*docker.ServiceDefinition, len(s.Members))
 	for i, member := range s.Members {
 		serviceDefinitions[i] = &docker.ServiceDefinition{
@@ -31,8 +37,8 @@ func GetEthconnectServiceDefinitions(s *types.Stack) []*docker.ServiceDefinition
 			Service: &docker.Service{
 				Image:         s.VersionManifest.Ethconnect.GetDockerImageString(),
 				ContainerName: fmt.Sprintf("%s_ethconnect_%v", s.Name, i),
-				Command:       "rest -U http://127.0.0.1:8080 -I ./abis -r http://geth:8545 -E ./events -d 3",
-				DependsOn:     map[string]map[string]string{"geth": {"condition": "service_started"}},
+				Command:       fmt.Sprintf("rest -U http://127.0.0.1:8080 -I ./abis -r %s -E ./events -d 3", rpcUrl),
+				DependsOn:     dependsOn,
 				Ports:         []string{fmt.Sprintf("%d:8080", member.ExposedConnectorPort)},
 				Volumes: []string{
 					fmt.Sprintf("ethconnect_abis_%s:/ethconnect/abis", member.ID),
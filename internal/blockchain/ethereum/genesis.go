@@ -22,6 +22,8 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"strings"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
 type Genesis struct {
@@ -37,6 +39,7 @@ type Genesis struct {
 	Number     string            `json:"number"`
 	GasUsed    string            `json:"gasUsed"`
 	ParentHash string            `json:"parentHash"`
+	BaseFee    string            `json:"baseFeePerGas,omitempty"`
 }
 
 type GenesisConfig struct {
@@ -50,6 +53,8 @@ type GenesisConfig struct {
 	ConstantinopleBlock int           `json:"constantinopleBlock"`
 	PetersburgBlock     int           `json:"petersburgBlock"`
 	IstanbulBlock       int           `json:"istanbulBlock"`
+	BerlinBlock         *int          `json:"berlinBlock,omitempty"`
+	LondonBlock         *int          `json:"londonBlock,omitempty"`
 	Clique              *CliqueConfig `json:"clique"`
 }
 
@@ -62,22 +67,49 @@ type Alloc struct {
 	Balance string `json:"balance"`
 }
 
-func CreateGenesisJson(addresses []string) *Genesis {
+const DefaultAccountBalance = "0x200000000000000000000000000000000000000000000000000000000000000"
+
+func CreateGenesisJson(addresses []string, options *types.EthereumOptions) *Genesis {
 
 	extraData := "0x0000000000000000000000000000000000000000000000000000000000000000"
 	alloc := make(map[string]*Alloc)
 
 	for _, address := range addresses {
 		alloc[address] = &Alloc{
-			Balance: "0x200000000000000000000000000000000000000000000000000000000000000",
+			Balance: DefaultAccountBalance,
 		}
 		extraData = extraData + address
 	}
 	extraData = strings.ReplaceAll(fmt.Sprintf("%-236s", extraData), " ", "0")
 
+	// Extra accounts are only funded - they are not added to the list of signers in the extraData
+	if options != nil {
+		for address, balance := range options.PrefundedAccounts {
+			alloc[strings.TrimPrefix(address, "0x")] = &Alloc{
+				Balance: balance,
+			}
+		}
+	}
+
+	var berlinBlock, londonBlock *int
+	if options != nil {
+		berlinBlock = options.BerlinBlock
+		londonBlock = options.LondonBlock
+	}
+
+	// Ethconnect sends transactions with a gas price of 0, which are only valid while the base fee is 0. The base fee
+	// starts at 0 in the genesis block, and only rises when a block uses more than half of the gas limit, so the
+	// gas limit starts at the target instead of growing towards it
+	gasLimit := options.GetGasLimit()
+	baseFee := ""
+	if options.LondonEnabled() {
+		gasLimit = options.GetGasTarget()
+		baseFee = "0x0"
+	}
+
 	return &Genesis{
 		Config: &GenesisConfig{
-			ChainId:             2021,
+			ChainId:             options.GetChainID(),
 			HomesteadBlock:      0,
 			Eip150Block:         0,
 			Eip150Hash:          "0x0000000000000000000000000000000000000000000000000000000000000000",
@@ -86,15 +118,17 @@ func CreateGenesisJson(addresses []string) *Genesis {
 			ByzantiumBlock:      0,
 			ConstantinopleBlock: 0,
 			IstanbulBlock:       0,
+			BerlinBlock:         berlinBlock,
+			LondonBlock:         londonBlock,
 			Clique: &CliqueConfig{
-				Period: 0,
+				Period: options.GetBlockPeriod(),
 				Epoch:  30000,
 			},
 		},
 		Nonce:      "0x0",
 		Timestamp:  "0x60edb1c7",
 		ExtraData:  extraData,
-		GasLimit:   "0x47b760",
+		GasLimit:   fmt.Sprintf("%#x", gasLimit),
 		Difficulty: "0x1",
 		MixHash:    "0x0000000000000000000000000000000000000000000000000000000000000000",
 		Coinbase:   "0x0000000000000000000000000000000000000000",
@@ -102,6 +136,7 @@ func CreateGenesisJson(addresses []string) *Genesis {
 		Number:     "0x0",
 		GasUsed:    "0x0",
 		ParentHash: "0x0000000000000000000000000000000000000000000000000000000000000000",
+		BaseFee:    baseFee,
 	}
 }
 
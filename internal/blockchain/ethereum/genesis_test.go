@@ -0,0 +1,100 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateGenesisJson(T *testing.T) {
+	zero, five := 0, 5
+	testCases := []struct {
+		name        string
+		options     *types.EthereumOptions
+		chainID     int
+		period      int
+		gasLimit    string
+		berlinBlock *int
+		londonBlock *int
+		baseFee     string
+	}{
+		{
+			name:     "defaults for older stacks",
+			options:  nil,
+			chainID:  2021,
+			gasLimit: "0x47b760",
+		},
+		{
+			name:     "chain id, period and gas limit",
+			options:  &types.EthereumOptions{ChainID: 1337, BlockPeriod: 5, GasLimit: 30000000},
+			chainID:  1337,
+			period:   5,
+			gasLimit: "0x1c9c380",
+		},
+		{
+			name:        "berlin in a later block",
+			options:     &types.EthereumOptions{BerlinBlock: &five},
+			chainID:     2021,
+			gasLimit:    "0x47b760",
+			berlinBlock: &five,
+		},
+		{
+			name:        "london starts at the gas target with a base fee of 0",
+			options:     &types.EthereumOptions{BerlinBlock: &zero, LondonBlock: &zero},
+			chainID:     2021,
+			gasLimit:    "0x2fefd800",
+			berlinBlock: &zero,
+			londonBlock: &zero,
+			baseFee:     "0x0",
+		},
+		{
+			name:        "london with a gas limit",
+			options:     &types.EthereumOptions{GasLimit: 30000000, BerlinBlock: &zero, LondonBlock: &zero},
+			chainID:     2021,
+			gasLimit:    "0x1c9c380",
+			berlinBlock: &zero,
+			londonBlock: &zero,
+			baseFee:     "0x0",
+		},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.name, func(t *testing.T) {
+			genesis := CreateGenesisJson([]string{"1f2a000000000000000000000000000000000001"}, tc.options)
+			assert.Equal(t, tc.chainID, genesis.Config.ChainId)
+			assert.Equal(t, tc.period, genesis.Config.Clique.Period)
+			assert.Equal(t, tc.gasLimit, genesis.GasLimit)
+			assert.Equal(t, tc.berlinBlock, genesis.Config.BerlinBlock)
+			assert.Equal(t, tc.londonBlock, genesis.Config.LondonBlock)
+			assert.Equal(t, tc.baseFee, genesis.BaseFee)
+		})
+	}
+}
+
+func TestCreateGenesisJsonAlloc(T *testing.T) {
+	genesis := CreateGenesisJson([]string{"1f2a000000000000000000000000000000000001"}, &types.EthereumOptions{
+		PrefundedAccounts: map[string]string{"0x1f2a000000000000000000000000000000000002": "1000"},
+	})
+	assert.Equal(T, DefaultAccountBalance, genesis.Alloc["1f2a000000000000000000000000000000000001"].Balance)
+	assert.Equal(T, "1000", genesis.Alloc["1f2a000000000000000000000000000000000002"].Balance)
+	// Prefunded accounts are not signers
+	assert.Contains(T, genesis.ExtraData, "1f2a000000000000000000000000000000000001")
+	assert.NotContains(T, genesis.ExtraData, "1f2a000000000000000000000000000000000002")
+	assert.Len(T, genesis.ExtraData, 236)
+}
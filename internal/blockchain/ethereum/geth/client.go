@@ -18,10 +18,14 @@ package geth
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type GethClient struct {
@@ -29,10 +33,20 @@ type GethClient struct {
 }
 
 type RpcRequest struct {
-	JsonRPC string   `json:"jsonrpc"`
-	ID      int      `json:"id"`
-	Method  string   `json:"method"`
-	Params  []string `json:"params"`
+	JsonRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type RpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error,omitempty"`
+}
+
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
 }
 
 func NewGethClient(rpcUrl string) *GethClient {
@@ -42,11 +56,90 @@ func NewGethClient(rpcUrl string) *GethClient {
 }
 
 func (g *GethClient) UnlockAccount(address string, password string) error {
+	return g.call("personal_unlockAccount", []interface{}{address, password}, nil)
+}
+
+// UnlockAccountIndefinitely keeps the account unlocked until the node restarts
+func (g *GethClient) UnlockAccountIndefinitely(address string, password string) error {
+	return g.call("personal_unlockAccount", []interface{}{address, password, 0}, nil)
+}
+
+// ImportRawKey imports a hex encoded private key (without the 0x prefix) into the node's keystore,
+// and returns the address of the account
+func (g *GethClient) ImportRawKey(privateKey string, password string) (string, error) {
+	var address string
+	err := g.call("personal_importRawKey", []interface{}{privateKey, password}, &address)
+	return address, err
+}
+
+// SendRawTransaction submits a signed, RLP encoded transaction, and returns its hash
+func (g *GethClient) SendRawTransaction(signedTx []byte) (string, error) {
+	var txHash string
+	err := g.call("eth_sendRawTransaction", []interface{}{"0x" + hex.EncodeToString(signedTx)}, &txHash)
+	return txHash, err
+}
+
+func (g *GethClient) ChainID() (*big.Int, error) {
+	return g.callForQuantity("eth_chainId", []interface{}{})
+}
+
+func (g *GethClient) GasPrice() (*big.Int, error) {
+	return g.callForQuantity("eth_gasPrice", []interface{}{})
+}
+
+// PendingNonce returns the nonce for the next transaction sent from an address, including any transactions that are still pending
+func (g *GethClient) PendingNonce(address string) (*big.Int, error) {
+	return g.callForQuantity("eth_getTransactionCount", []interface{}{address, "pending"})
+}
+
+// GetCode returns the hex encoded code of the contract at an address, which is "0x" if there is no contract
+func (g *GethClient) GetCode(address string) (string, error) {
+	var code string
+	err := g.call("eth_getCode", []interface{}{address, "latest"}, &code)
+	return code, err
+}
+
+func (g *GethClient) WaitForTransaction(txHash string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var receipt map[string]interface{}
+		if err := g.call("eth_getTransactionReceipt", []interface{}{txHash}, &receipt); err != nil {
+			return err
+		}
+		if receipt != nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("transaction %s was not mined within %s", txHash, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// AddPeer connects the node to another node, given its enode URL
+func (g *GethClient) AddPeer(enode string) error {
+	return g.call("admin_addPeer", []interface{}{enode}, nil)
+}
+
+// callForQuantity calls a method that returns a hex encoded integer
+func (g *GethClient) callForQuantity(method string, params []interface{}) (*big.Int, error) {
+	var result string
+	if err := g.call(method, params, &result); err != nil {
+		return nil, err
+	}
+	quantity, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("%s returned an invalid quantity '%s'", method, result)
+	}
+	return quantity, nil
+}
+
+func (g *GethClient) call(method string, params []interface{}, result interface{}) error {
 	requestBody, err := json.Marshal(&RpcRequest{
 		JsonRPC: "2.0",
 		ID:      0,
-		Method:  "personal_unlockAccount",
-		Params:  []string{address, password},
+		Method:  method,
+		Params:  params,
 	})
 	if err != nil {
 		return err
@@ -69,5 +162,15 @@ func (g *GethClient) UnlockAccount(address string, password string) error {
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("%d %s", resp.StatusCode, responseBody)
 	}
+	var rpcResponse *RpcResponse
+	if err := json.Unmarshal(responseBody, &rpcResponse); err != nil {
+		return err
+	}
+	if rpcResponse.Error != nil {
+		return fmt.Errorf("%s failed: %s", method, rpcResponse.Error.Message)
+	}
+	if result != nil && len(rpcResponse.Result) > 0 {
+		return json.Unmarshal(rpcResponse.Result, result)
+	}
 	return nil
 }
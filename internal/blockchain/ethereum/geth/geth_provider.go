@@ -32,6 +32,8 @@ import (
 	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
+const gethLegacyImage = "ethereum/client-go:release-1.9"
+
 type GethProvider struct {
 	Log     log.Logger
 	Verbose bool
@@ -47,15 +49,28 @@ func (p *GethProvider) WriteConfig() error {
 		}
 	}
 
-	// Create genesis.json
-	addresses := make([]string, len(p.Stack.Members))
-	for i, member := range p.Stack.Members {
-		// Drop the 0x on the front of the address here because that's what geth is expecting in the genesis.json
-		addresses[i] = member.Address[2:]
+	// Create genesis.json, unless the stack is joining an existing network with its own genesis block
+	if p.isJoined() {
+		if err := ioutil.WriteFile(filepath.Join(stackDir, "blockchain", "genesis.json"), p.Stack.Ethereum.Genesis, 0755); err != nil {
+			return err
+		}
+	} else {
+		addresses := make([]string, len(p.Stack.Members))
+		for i, member := range p.Stack.Members {
+			// Drop the 0x on the front of the address here because that's what geth is expecting in the genesis.json
+			addresses[i] = member.Address[2:]
+		}
+		genesis := ethereum.CreateGenesisJson(addresses, p.Stack.Ethereum)
+		if err := genesis.WriteGenesisJson(filepath.Join(stackDir, "blockchain", "genesis.json")); err != nil {
+			return err
+		}
 	}
-	genesis := ethereum.CreateGenesisJson(addresses)
-	if err := genesis.WriteGenesisJson(filepath.Join(stackDir, "blockchain", "genesis.json")); err != nil {
-		return err
+
+	// A fixed node key gives the node a stable enode URL, so that nodes on other machines can peer with it
+	if p.Stack.Ethereum != nil && p.Stack.Ethereum.NodeKey != "" {
+		if err := ioutil.WriteFile(filepath.Join(stackDir, "blockchain", "nodekey"), []byte(p.Stack.Ethereum.NodeKey[2:]), 0755); err != nil {
+			return err
+		}
 	}
 
 	// Write the password that will be used to encrypt the private key
@@ -73,7 +88,7 @@ func (p *GethProvider) FirstTimeSetup() error {
 
 	// Mount the directory containing all members' private keys and password, and import the accounts using the geth CLI
 	for _, member := range p.Stack.Members {
-		if err := docker.RunDockerCommand(constants.StacksDir, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/geth", gethConfigDir), "-v", fmt.Sprintf("%s:/data", volumeName), "ethereum/client-go:release-1.9", "--nousb", "account", "import", "--password", "/geth/password", "--keystore", "/data/keystore", fmt.Sprintf("/geth/%s/keyfile", member.ID)); err != nil {
+		if err := docker.RunDockerCommand(constants.StacksDir, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/geth", gethConfigDir), "-v", fmt.Sprintf("%s:/data", volumeName), p.getGethImage(), "--nousb", "account", "import", "--password", "/geth/password", "--keystore", "/data/keystore", fmt.Sprintf("/geth/%s/keyfile", member.ID)); err != nil {
 			return err
 		}
 	}
@@ -88,8 +103,14 @@ func (p *GethProvider) FirstTimeSetup() error {
 		return err
 	}
 
+	if p.Stack.Ethereum != nil && p.Stack.Ethereum.NodeKey != "" {
+		if err := docker.CopyFileToVolume(volumeName, path.Join(gethConfigDir, "nodekey"), "nodekey", p.Verbose); err != nil {
+			return err
+		}
+	}
+
 	// Initialize the genesis block
-	if err := docker.RunDockerCommand(constants.StacksDir, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/data", volumeName), "ethereum/client-go:release-1.9", "--datadir", "/data", "--nousb", "init", "/data/genesis.json"); err != nil {
+	if err := docker.RunDockerCommand(constants.StacksDir, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/data", volumeName), p.getGethImage(), "--datadir", "/data", "--nousb", "init", "/data/genesis.json"); err != nil {
 		return err
 	}
 
@@ -118,11 +139,29 @@ func (p *GethProvider) PostStart() error {
 			}
 		}
 	}
+	// Discovery is disabled, so connect to the nodes of any network this stack has joined directly
+	for _, enode := range p.Stack.Ethereum.GetBootnodes() {
+		p.Log.Info(fmt.Sprintf("adding peer %s", enode))
+		if err := gethClient.AddPeer(enode); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (p *GethProvider) DeploySmartContracts() error {
-	return ethereum.DeployContracts(p.Stack, p.Log, p.Verbose)
+	if err := ethereum.DeployContracts(p.Stack, p.Log, p.Verbose); err != nil {
+		return err
+	}
+	return ethereum.DeployCustomContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *GethProvider) UpgradeSmartContracts() error {
+	return ethereum.UpgradeContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *GethProvider) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	return ethereum.DeployContractFile(p.Stack, p.Log, filename, options.ContractName, options.Name, options.Args, options.SolcBasePath, p.Verbose)
 }
 
 func (p *GethProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
@@ -133,22 +172,43 @@ func (p *GethProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition
 			addresses = addresses + ","
 		}
 	}
-	gethCommand := fmt.Sprintf(`--datadir /data --syncmode 'full' --port 30311 --rpcvhosts=* --rpccorsdomain "*" --miner.gastarget 804247552 --rpc --rpcaddr "0.0.0.0" --rpcport 8545 --rpcapi 'admin,personal,db,eth,net,web3,txpool,miner,clique' --networkid 2021 --miner.gasprice 0 --unlock '%s' --password /data/password --mine --nousb --allow-insecure-unlock --nodiscover`, addresses)
+	// After London the gas target is deprecated, and the gas limit flag sets the target block gas limit instead
+	gasFlag := fmt.Sprintf("--miner.gastarget %d", p.Stack.Ethereum.GetGasTarget())
+	if p.Stack.Ethereum.LondonEnabled() {
+		gasFlag = fmt.Sprintf("--miner.gaslimit %d", p.Stack.Ethereum.GetGasTarget())
+	}
+	// Geth 1.10 removed the --rpc flags in favour of --http, which geth 1.9 stacks keep using
+	rpcFlags := `--rpcvhosts=* --rpccorsdomain "*" %s --rpc --rpcaddr "0.0.0.0" --rpcport 8545 --rpcapi`
+	if p.getGethImage() != gethLegacyImage {
+		rpcFlags = `--http.vhosts=* --http.corsdomain "*" %s --http --http.addr "0.0.0.0" --http.port 8545 --http.api`
+	}
+	gethCommand := fmt.Sprintf(`--datadir /data --syncmode 'full' --port 30311 `+rpcFlags+` 'admin,personal,db,eth,net,web3,txpool,miner,clique' --networkid %d --miner.gasprice 0 --unlock '%s' --password /data/password --nousb --allow-insecure-unlock --nodiscover`, gasFlag, p.Stack.Ethereum.GetChainID(), addresses)
+	// Only the nodes that created the network are signers in its genesis block
+	if !p.isJoined() {
+		gethCommand += " --mine"
+	}
+	if p.Stack.Ethereum != nil && p.Stack.Ethereum.NodeKey != "" {
+		gethCommand += " --nodekey /data/nodekey"
+	}
+	ports := []string{fmt.Sprintf("%d:8545", p.Stack.ExposedBlockchainPort)}
+	if p.Stack.ExposedBlockchainP2PPort != 0 {
+		ports = append(ports, fmt.Sprintf("%d:30311", p.Stack.ExposedBlockchainP2PPort))
+	}
 
 	serviceDefinitions := make([]*docker.ServiceDefinition, 1)
 	serviceDefinitions[0] = &docker.ServiceDefinition{
 		ServiceName: "geth",
 		Service: &docker.Service{
-			Image:         "ethereum/client-go:release-1.9",
+			Image:         p.getGethImage(),
 			ContainerName: fmt.Sprintf("%s_geth", p.Stack.Name),
 			Command:       gethCommand,
 			Volumes:       []string{"geth:/data"},
 			Logging:       docker.StandardLogOptions,
-			Ports:         []string{fmt.Sprintf("%d:8545", p.Stack.ExposedBlockchainPort)},
+			Ports:         ports,
 		},
 		VolumeNames: []string{"geth"},
 	}
-	serviceDefinitions = append(serviceDefinitions, ethconnect.GetEthconnectServiceDefinitions(p.Stack)...)
+	serviceDefinitions = append(serviceDefinitions, ethconnect.GetEthconnectServiceDefinitions(p.Stack, "http://geth:8545", "geth")...)
 	return serviceDefinitions
 }
 
@@ -163,7 +223,7 @@ func (p *GethProvider) GetFireflyConfig(m *types.Member) (blockchainConfig *core
 		Ethereum: &core.EthereumConfig{
 			Ethconnect: &core.EthconnectConfig{
 				URL:      p.getEthconnectURL(m),
-				Instance: "/contracts/firefly",
+				Instance: "/contracts/" + ethereum.GetFireFlyContractName(p.Stack),
 				Topic:    m.ID,
 			},
 		},
@@ -175,6 +235,18 @@ func (p *GethProvider) Reset() error {
 	return nil
 }
 
+func (p *GethProvider) getGethImage() string {
+	// Geth 1.9 predates the Berlin and London hard forks
+	if p.Stack.Ethereum.LondonEnabled() || (p.Stack.Ethereum != nil && p.Stack.Ethereum.BerlinBlock != nil) {
+		return "ethereum/client-go:release-1.10"
+	}
+	return gethLegacyImage
+}
+
+func (p *GethProvider) isJoined() bool {
+	return p.Stack.Ethereum != nil && len(p.Stack.Ethereum.Genesis) > 0
+}
+
 func (p *GethProvider) getEthconnectURL(member *types.Member) string {
 	if !member.External {
 		return fmt.Sprintf("http://ethconnect_%s:8080", member.ID)
@@ -0,0 +1,145 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoterpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	secp256k1 "github.com/btcsuite/btcd/btcec"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/geth"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// Each member account is sent 1 ether from the funding account to pay for gas
+var memberFundingAmount = big.NewInt(1000000000000000000)
+
+// The gas used by a plain transfer of ether
+const transferGas = 21000
+
+// FundMembers sends each member account ether from the funding account. The transactions are signed
+// here and submitted with eth_sendRawTransaction, so the funding key is never sent to the remote node
+func FundMembers(l log.Logger, rpcURL string, fundingKey string, members []*types.Member) error {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(fundingKey, "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return errors.New("invalid funding key - please provide a 32 byte hex encoded private key")
+	}
+	privateKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), keyBytes)
+	fundingAddress := getAddress(privateKey)
+
+	client := geth.NewGethClient(rpcURL)
+	chainID, err := client.ChainID()
+	if err != nil {
+		return err
+	}
+	gasPrice, err := client.GasPrice()
+	if err != nil {
+		return err
+	}
+	nonce, err := client.PendingNonce(fundingAddress)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		l.Info(fmt.Sprintf("funding account %s for member %s from %s", member.Address, member.ID, fundingAddress))
+		to, err := hex.DecodeString(strings.TrimPrefix(member.Address, "0x"))
+		if err != nil {
+			return err
+		}
+		signedTx, err := signTransaction(privateKey, chainID, nonce, gasPrice, big.NewInt(transferGas), to, memberFundingAmount)
+		if err != nil {
+			return err
+		}
+		txHash, err := client.SendRawTransaction(signedTx)
+		if err != nil {
+			return err
+		}
+		if err := client.WaitForTransaction(txHash, time.Minute); err != nil {
+			return err
+		}
+		nonce = new(big.Int).Add(nonce, big.NewInt(1))
+	}
+	return nil
+}
+
+// signTransaction returns the RLP encoding of a legacy transaction with no data,
+// signed for the given chain as described in EIP-155
+func signTransaction(privateKey *secp256k1.PrivateKey, chainID, nonce, gasPrice, gas *big.Int, to []byte, value *big.Int) ([]byte, error) {
+	fields := [][]byte{
+		rlpEncodeBytes(nonce.Bytes()),
+		rlpEncodeBytes(gasPrice.Bytes()),
+		rlpEncodeBytes(gas.Bytes()),
+		rlpEncodeBytes(to),
+		rlpEncodeBytes(value.Bytes()),
+		rlpEncodeBytes([]byte{}),
+	}
+	unsigned := append(append([][]byte{}, fields...), rlpEncodeBytes(chainID.Bytes()), rlpEncodeBytes([]byte{}), rlpEncodeBytes([]byte{}))
+	hash := keccak256(rlpEncodeList(unsigned))
+
+	// The compact signature is the recovery id plus 27, followed by R and S
+	sig, err := secp256k1.SignCompact(secp256k1.S256(), privateKey, hash, false)
+	if err != nil {
+		return nil, err
+	}
+	recoveryID := int64(sig[0] - 27)
+	v := new(big.Int).Add(new(big.Int).Mul(chainID, big.NewInt(2)), big.NewInt(35+recoveryID))
+	r := new(big.Int).SetBytes(sig[1:33])
+	s := new(big.Int).SetBytes(sig[33:65])
+
+	signed := append(append([][]byte{}, fields...), rlpEncodeBytes(v.Bytes()), rlpEncodeBytes(r.Bytes()), rlpEncodeBytes(s.Bytes()))
+	return rlpEncodeList(signed), nil
+}
+
+func getAddress(privateKey *secp256k1.PrivateKey) string {
+	publicKey := privateKey.PubKey().SerializeUncompressed()
+	return "0x" + hex.EncodeToString(keccak256(publicKey[1:])[12:])
+}
+
+func keccak256(data []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func rlpEncodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpEncodeList(items [][]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	lengthBytes := big.NewInt(int64(length)).Bytes()
+	return append([]byte{offset + 55 + byte(len(lengthBytes))}, lengthBytes...)
+}
@@ -0,0 +1,44 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoterpc
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	secp256k1 "github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+)
+
+// The example transaction from EIP-155
+func TestSignTransaction(T *testing.T) {
+	keyBytes, _ := hex.DecodeString("4646464646464646464646464646464646464646464646464646464646464646")
+	privateKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), keyBytes)
+	to, _ := hex.DecodeString("3535353535353535353535353535353535353535")
+	value, _ := new(big.Int).SetString("1000000000000000000", 10)
+
+	signedTx, err := signTransaction(privateKey, big.NewInt(1), big.NewInt(9), big.NewInt(20000000000), big.NewInt(21000), to, value)
+	assert.NoError(T, err)
+	assert.Equal(T, "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83", hex.EncodeToString(signedTx))
+}
+
+func TestGetAddress(T *testing.T) {
+	keyBytes, _ := hex.DecodeString("4646464646464646464646464646464646464646464646464646464646464646")
+	privateKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), keyBytes)
+	assert.Equal(T, "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", getAddress(privateKey))
+}
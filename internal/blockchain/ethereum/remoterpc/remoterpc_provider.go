@@ -0,0 +1,167 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoterpc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/ethconnect"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/geth"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// RemoteRPCProvider connects the stack's ethconnect instances to an existing Ethereum node over JSON-RPC,
+// rather than running a blockchain node in the stack. Ethconnect has the node sign transactions, so member
+// keys are imported into the node's keystore with a password generated for the stack, and stay unlocked
+// while the node runs. Anyone with access to the node's RPC API can send transactions from the members'
+// accounts, so the node must only be shared with trusted users
+type RemoteRPCProvider struct {
+	Log     log.Logger
+	Verbose bool
+	Stack   *types.Stack
+}
+
+func (p *RemoteRPCProvider) WriteConfig() error {
+	return nil
+}
+
+func (p *RemoteRPCProvider) FirstTimeSetup() error {
+	client := p.getClient()
+	for _, member := range p.Stack.Members {
+		p.Log.Info(fmt.Sprintf("importing account for member %s", member.ID))
+		if err := p.importKey(client, member.PrivateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *RemoteRPCProvider) PreStart() error {
+	return nil
+}
+
+func (p *RemoteRPCProvider) PostStart() error {
+	client := p.getClient()
+	for _, m := range p.Stack.Members {
+		p.Log.Info(fmt.Sprintf("unlocking account for member %s", m.ID))
+		if err := client.UnlockAccountIndefinitely(m.Address, p.Stack.Ethereum.GetAccountPassword()); err != nil {
+			return fmt.Errorf("unable to unlock account %s for member %s: %s", m.Address, m.ID, err)
+		}
+	}
+	return nil
+}
+
+func (p *RemoteRPCProvider) DeploySmartContracts() error {
+	if err := ethereum.DeployContracts(p.Stack, p.Log, p.Verbose); err != nil {
+		return err
+	}
+	return ethereum.DeployCustomContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *RemoteRPCProvider) UpgradeSmartContracts() error {
+	return ethereum.UpgradeContracts(p.Stack, p.Log, p.Verbose)
+}
+
+func (p *RemoteRPCProvider) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	return ethereum.DeployContractFile(p.Stack, p.Log, filename, options.ContractName, options.Name, options.Args, options.SolcBasePath, p.Verbose)
+}
+
+func (p *RemoteRPCProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	return ethconnect.GetEthconnectServiceDefinitions(p.Stack, p.Stack.Ethereum.RPCURL, "")
+}
+
+func (p *RemoteRPCProvider) GetFireflyConfig(m *types.Member) (blockchainConfig *core.BlockchainConfig, orgConfig *core.OrgConfig) {
+	orgConfig = &core.OrgConfig{
+		Name:     m.OrgName,
+		Identity: m.Address,
+	}
+
+	blockchainConfig = &core.BlockchainConfig{
+		Type: "ethereum",
+		Ethereum: &core.EthereumConfig{
+			Ethconnect: &core.EthconnectConfig{
+				URL:      p.getEthconnectURL(m),
+				Instance: "/contracts/" + ethereum.GetFireFlyContractName(p.Stack),
+				Topic:    m.ID,
+			},
+		},
+	}
+	return
+}
+
+func (p *RemoteRPCProvider) Reset() error {
+	return nil
+}
+
+func (p *RemoteRPCProvider) getClient() *geth.GethClient {
+	return geth.NewGethClient(GetHostRPCURL(p.Stack.Ethereum.RPCURL))
+}
+
+// CheckContract checks that there is a contract at the address on the remote chain, so that a wrong address
+// is found when the stack is created rather than when FireFly first uses the contract
+func CheckContract(rpcURL, address string) error {
+	code, err := geth.NewGethClient(GetHostRPCURL(rpcURL)).GetCode(address)
+	if err != nil {
+		return fmt.Errorf("failed to check the contract at %s: %s", address, err)
+	}
+	if code == "" || code == "0x" {
+		return fmt.Errorf("there is no contract at %s on the remote chain", address)
+	}
+	return nil
+}
+
+// GetHostRPCURL returns the URL for the CLI to reach the node at from the host. The RPC URL is used from
+// inside the stack's containers, which reach services on the host at host.docker.internal
+func GetHostRPCURL(rpcURL string) string {
+	u, err := url.Parse(rpcURL)
+	if err != nil || u.Hostname() != "host.docker.internal" {
+		return rpcURL
+	}
+	if port := u.Port(); port != "" {
+		u.Host = "localhost:" + port
+	} else {
+		u.Host = "localhost"
+	}
+	return u.String()
+}
+
+// importKey adds a member's key to the remote node's keystore. Keys that were imported
+// by an earlier run of the stack are left as they are
+func (p *RemoteRPCProvider) importKey(client *geth.GethClient, privateKey string) error {
+	if _, err := client.ImportRawKey(strings.TrimPrefix(privateKey, "0x"), p.Stack.Ethereum.GetAccountPassword()); err != nil && !isAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func (p *RemoteRPCProvider) getEthconnectURL(member *types.Member) string {
+	if !member.External {
+		return fmt.Sprintf("http://ethconnect_%s:8080", member.ID)
+	} else {
+		return fmt.Sprintf("http://127.0.0.1:%v", member.ExposedConnectorPort)
+	}
+}
+
+func isAlreadyExists(err error) bool {
+	return strings.Contains(err.Error(), "already exists")
+}
@@ -0,0 +1,40 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoterpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostRPCURL(T *testing.T) {
+	testCases := []struct {
+		rpcURL   string
+		expected string
+	}{
+		{rpcURL: "http://host.docker.internal:8545", expected: "http://localhost:8545"},
+		{rpcURL: "https://host.docker.internal/rpc", expected: "https://localhost/rpc"},
+		{rpcURL: "http://testnet.example.com:8545", expected: "http://testnet.example.com:8545"},
+		{rpcURL: "http://172.17.0.1:8545", expected: "http://172.17.0.1:8545"},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.rpcURL, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetHostRPCURL(tc.rpcURL))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+type SolcOutput struct {
+	Contracts map[string]*SolcContract `json:"contracts"`
+	Version   string                   `json:"version"`
+}
+
+type SolcContract struct {
+	ABI json.RawMessage `json:"abi"`
+	Bin string          `json:"bin"`
+}
+
+// ReadContract reads a contract from either a compiled contract JSON file, or a
+// Solidity source file. The contract in a Solidity file is selected by contractName,
+// or if that is empty, is the only contract in the file or the one matching the file
+// name. Imports in a Solidity file are resolved within basePath, which defaults to
+// the file's own directory.
+func ReadContract(filename string, contractName string, basePath string, verbose bool) (*types.Contract, error) {
+	if strings.ToLower(filepath.Ext(filename)) == ".sol" {
+		return CompileContract(filename, contractName, basePath, verbose)
+	}
+	if contractName != "" {
+		return nil, errors.New("a contract can only be selected from a Solidity source file")
+	}
+	return ReadCompiledContract(filename)
+}
+
+func CompileContract(filename string, contractName string, basePath string, verbose bool) (*types.Contract, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	// Mount the whole base path, so that any relative imports in the contract can be resolved
+	sourceDir, sourceFile := filepath.Split(absPath)
+	if basePath == "" {
+		basePath = sourceDir
+	}
+	if basePath, err = filepath.Abs(basePath); err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(basePath, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("'%s' is not inside the solc base path '%s'", filename, basePath)
+	}
+	// Only stdout is parsed, as solc writes any warnings to stderr
+	output, err := docker.RunDockerCommandStdout(basePath, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/sources", basePath), constants.SolcImageName, "--base-path", "/sources", "--optimize", "--combined-json", "abi,bin", "/sources/"+filepath.ToSlash(relPath))
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := parseSolcOutput(output, sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	name, err := selectContract(candidates, contractName, sourceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older versions of solc return the ABI as a JSON encoded string rather than an array
+	abiBytes := []byte(candidates[name].ABI)
+	var abiString string
+	if err := json.Unmarshal(abiBytes, &abiString); err == nil {
+		abiBytes = []byte(abiString)
+	}
+	var abi interface{}
+	if err := json.Unmarshal(abiBytes, &abi); err != nil {
+		return nil, fmt.Errorf("failed to parse ABI for contract '%s': %s", name, err)
+	}
+
+	return &types.Contract{
+		ContractName: name,
+		ABI:          abi,
+		Bytecode:     "0x" + candidates[name].Bin,
+	}, nil
+}
+
+// parseSolcOutput returns the deployable contracts declared in the source file, keyed by contract name. Contracts
+// from any of the file's imports are left out
+func parseSolcOutput(output string, sourceFile string) (map[string]*SolcContract, error) {
+	var solcOutput *SolcOutput
+	if err := json.Unmarshal([]byte(output), &solcOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse solc output: %s", err)
+	}
+	if solcOutput == nil {
+		return nil, errors.New("failed to parse solc output: no contracts were returned")
+	}
+	candidates := make(map[string]*SolcContract)
+	for key, contract := range solcOutput.Contracts {
+		i := strings.LastIndex(key, ":")
+		if i < 0 || contract == nil || contract.Bin == "" || filepath.Base(key[:i]) != sourceFile {
+			continue
+		}
+		candidates[key[i+1:]] = contract
+	}
+	return candidates, nil
+}
+
+// selectContract returns the name of the contract named contractName, or if that is empty, the only contract or
+// the one matching the file name
+func selectContract(candidates map[string]*SolcContract, contractName string, sourceFile string) (string, error) {
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "", fmt.Errorf("no deployable contracts found in '%s'", sourceFile)
+	}
+	if contractName != "" {
+		for _, name := range names {
+			if strings.EqualFold(name, contractName) {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("'%s' does not contain a deployable contract named '%s' - please use one of: %s", sourceFile, contractName, strings.Join(names, ", "))
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, strings.TrimSuffix(sourceFile, filepath.Ext(sourceFile))) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' contains more than one contract - please specify which one to use from: %s", sourceFile, strings.Join(names, ", "))
+}
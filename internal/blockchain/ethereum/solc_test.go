@@ -0,0 +1,101 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSolcOutput(T *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "contracts in the file",
+			output:   `{"contracts":{"/sources/Tokens.sol:Coin":{"abi":[],"bin":"6080"},"/sources/Tokens.sol:Badge":{"abi":[],"bin":"6081"}},"version":"0.8.11"}`,
+			expected: []string{"Badge", "Coin"},
+		},
+		{
+			name:     "imports and interfaces are left out",
+			output:   `{"contracts":{"/sources/Tokens.sol:Coin":{"abi":[],"bin":"6080"},"/sources/lib/Ownable.sol:Ownable":{"abi":[],"bin":"6082"},"/sources/Tokens.sol:ICoin":{"abi":[],"bin":""}}}`,
+			expected: []string{"Coin"},
+		},
+		{
+			name:   "not json",
+			output: "Error: Source file requires different compiler version",
+			err:    "failed to parse solc output",
+		},
+		{
+			name:   "null",
+			output: "null",
+			err:    "no contracts were returned",
+		},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.name, func(t *testing.T) {
+			candidates, err := parseSolcOutput(tc.output, "Tokens.sol")
+			if tc.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			names := []string{}
+			for name := range candidates {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
+
+func TestSelectContract(T *testing.T) {
+	one := map[string]*SolcContract{"Coin": {Bin: "6080"}}
+	two := map[string]*SolcContract{"Coin": {Bin: "6080"}, "Tokens": {Bin: "6081"}}
+	testCases := []struct {
+		name         string
+		candidates   map[string]*SolcContract
+		contractName string
+		sourceFile   string
+		expected     string
+		err          string
+	}{
+		{name: "only contract", candidates: one, sourceFile: "Other.sol", expected: "Coin"},
+		{name: "selected", candidates: two, contractName: "Coin", sourceFile: "Tokens.sol", expected: "Coin"},
+		{name: "selected ignoring case", candidates: two, contractName: "coin", sourceFile: "Tokens.sol", expected: "Coin"},
+		{name: "file name", candidates: two, sourceFile: "Tokens.sol", expected: "Tokens"},
+		{name: "selected contract not found", candidates: one, contractName: "Badge", sourceFile: "Tokens.sol", err: "'Tokens.sol' does not contain a deployable contract named 'Badge' - please use one of: Coin"},
+		{name: "ambiguous", candidates: two, sourceFile: "Other.sol", err: "'Other.sol' contains more than one contract - please specify which one to use from: Coin, Tokens"},
+		{name: "none", candidates: map[string]*SolcContract{}, sourceFile: "Other.sol", err: "no deployable contracts found in 'Other.sol'"},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.name, func(t *testing.T) {
+			name, err := selectContract(tc.candidates, tc.contractName, tc.sourceFile)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
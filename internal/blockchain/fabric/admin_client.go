@@ -0,0 +1,219 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+)
+
+const FabricToolsImageName = "hyperledger/fabric-tools:2.3"
+
+// The peer builds chaincode in the ccenv image and runs Go chaincode in the baseos image
+const FabricCCEnvImageName = "hyperledger/fabric-ccenv:2.3"
+const FabricBaseOSImageName = "hyperledger/fabric-baseos:2.3"
+
+// The peer CLI reports failed queries as "Error: query failed with status: <code> - <message>"
+var queryStatusRegex = regexp.MustCompile(`query failed with status: (\d+)`)
+
+// PeerContext holds the connection details and admin identity used to run
+// peer CLI commands against a peer on behalf of its org
+type PeerContext struct {
+	Address       string
+	MSPID         string
+	TLSRootCert   string
+	MSPConfigPath string
+}
+
+// OrdererContext holds the connection details for an orderer, and the admin
+// identity used with its channel participation API
+type OrdererContext struct {
+	Address         string
+	AdminAddress    string
+	TLSHostname     string
+	TLSCACert       string
+	AdminTLSCACert  string
+	AdminClientCert string
+	AdminClientKey  string
+}
+
+func NewPeerContext(orgDomain, mspID, peerName string) *PeerContext {
+	orgDir := path.Join("/etc/firefly/organizations/peerOrganizations", orgDomain)
+	return &PeerContext{
+		Address:       peerName + ":7051",
+		MSPID:         mspID,
+		TLSRootCert:   path.Join(orgDir, "peers", fmt.Sprintf("%s.%s", peerName, orgDomain), "tls/ca.crt"),
+		MSPConfigPath: path.Join(orgDir, "users", fmt.Sprintf("Admin@%s", orgDomain), "msp"),
+	}
+}
+
+func NewOrdererContext(orgDomain, ordererName string) *OrdererContext {
+	orgDir := path.Join("/etc/firefly/organizations/ordererOrganizations", orgDomain)
+	adminTLSDir := path.Join(orgDir, "users", fmt.Sprintf("Admin@%s", orgDomain), "tls")
+	return &OrdererContext{
+		Address:         ordererName + ":7050",
+		AdminAddress:    ordererName + ":7053",
+		TLSHostname:     ordererName,
+		TLSCACert:       path.Join(orgDir, "orderers", fmt.Sprintf("%s.%s", ordererName, orgDomain), "msp/tlscacerts", fmt.Sprintf("tlsca.%s-cert.pem", orgDomain)),
+		AdminTLSCACert:  path.Join(adminTLSDir, "ca.crt"),
+		AdminClientCert: path.Join(adminTLSDir, "client.crt"),
+		AdminClientKey:  path.Join(adminTLSDir, "client.key"),
+	}
+}
+
+func (c *PeerContext) env() []string {
+	return []string{
+		"CORE_PEER_ADDRESS=" + c.Address,
+		"CORE_PEER_TLS_ENABLED=true",
+		"CORE_PEER_TLS_ROOTCERT_FILE=" + c.TLSRootCert,
+		"CORE_PEER_LOCALMSPID=" + c.MSPID,
+		"CORE_PEER_MSPCONFIGPATH=" + c.MSPConfigPath,
+	}
+}
+
+func (o *OrdererContext) args() []string {
+	return []string{"-o", o.Address, "--ordererTLSHostnameOverride", o.TLSHostname, "--tls", "--cafile", o.TLSCACert}
+}
+
+// AdminClient runs Fabric admin operations in a single long-lived fabric-tools container
+// attached to the stack's network, instead of starting a new container for every command.
+// Chaincode packages in the stack's contracts directory are available under /contracts
+type AdminClient struct {
+	ContainerName string
+	Verbose       bool
+}
+
+func StartAdminClient(stackName string, verbose bool) (*AdminClient, error) {
+	stackDir := path.Join(constants.StacksDir, stackName)
+	contractsDir := path.Join(stackDir, "contracts")
+	if err := os.MkdirAll(contractsDir, 0755); err != nil {
+		return nil, err
+	}
+	c := &AdminClient{
+		ContainerName: fmt.Sprintf("%s_fabric_tools", stackName),
+		Verbose:       verbose,
+	}
+	// Clean up a container that may have been left behind by a previous run that was interrupted
+	docker.RunDockerCommandBuffered(stackDir, verbose, "rm", "-f", c.ContainerName)
+	if err := docker.RunDockerCommand(stackDir, verbose, verbose, "run", "-d", "--rm", "--name", c.ContainerName, fmt.Sprintf("--network=%s_default", stackName), "-v", fmt.Sprintf("%s_firefly_fabric:/etc/firefly", stackName), "-v", fmt.Sprintf("%s:/contracts", contractsDir), FabricToolsImageName, "sleep", "infinity"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *AdminClient) Stop() error {
+	_, err := docker.RunDockerCommandBuffered(".", c.Verbose, "rm", "-f", c.ContainerName)
+	return err
+}
+
+func (c *AdminClient) OrdererJoinChannel(orderer *OrdererContext, channel, blockPath string) error {
+	return c.run(nil, "osnadmin", "channel", "join", "--channelID", channel, "--config-block", blockPath, "-o", orderer.AdminAddress, "--ca-file", orderer.AdminTLSCACert, "--client-cert", orderer.AdminClientCert, "--client-key", orderer.AdminClientKey)
+}
+
+func (c *AdminClient) JoinChannel(peer *PeerContext, blockPath string) error {
+	return c.run(peer.env(), "peer", "channel", "join", "-b", blockPath)
+}
+
+func (c *AdminClient) InstallChaincode(peer *PeerContext, packagePath string) error {
+	return c.run(peer.env(), "peer", "lifecycle", "chaincode", "install", packagePath)
+}
+
+func (c *AdminClient) QueryInstalled(peer *PeerContext) (*QueryInstalledResponse, error) {
+	str, err := c.exec(peer.env(), "peer", "lifecycle", "chaincode", "queryinstalled", "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var res *QueryInstalledResponse
+	if err := json.Unmarshal([]byte(str), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// QueryCommitted returns the committed definition of a chaincode, or nil if it has not been committed on the channel
+func (c *AdminClient) QueryCommitted(peer *PeerContext, channel, name string) (*QueryCommittedResponse, error) {
+	str, err := c.exec(peer.env(), "peer", "lifecycle", "chaincode", "querycommitted", "--channelID", channel, "--name", name, "--output", "json")
+	if err != nil {
+		// The peer returns a 404 if the chaincode namespace is not defined on the channel
+		if queryStatusCode(err) == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var res *QueryCommittedResponse
+	if err := json.Unmarshal([]byte(str), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *AdminClient) ApproveChaincode(peer *PeerContext, orderer *OrdererContext, channel, name, version, packageID string, sequence int, signaturePolicy string) error {
+	args := []string{"peer", "lifecycle", "chaincode", "approveformyorg", "--channelID", channel, "--name", name, "--version", version, "--package-id", packageID, "--sequence", fmt.Sprint(sequence)}
+	args = append(args, orderer.args()...)
+	args = append(args, signaturePolicyArgs(signaturePolicy)...)
+	return c.run(peer.env(), args...)
+}
+
+func (c *AdminClient) CommitChaincode(peer *PeerContext, orderer *OrdererContext, channel, name, version string, sequence int, signaturePolicy string) error {
+	args := []string{"peer", "lifecycle", "chaincode", "commit", "--channelID", channel, "--name", name, "--version", version, "--sequence", fmt.Sprint(sequence)}
+	args = append(args, orderer.args()...)
+	args = append(args, signaturePolicyArgs(signaturePolicy)...)
+	return c.run(peer.env(), args...)
+}
+
+// queryStatusCode returns the status code of a failed peer query, or 0 if the command failed for another reason
+func queryStatusCode(err error) int {
+	match := queryStatusRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(match[1])
+	return code
+}
+
+// The approved and committed definitions must use the same endorsement policy
+func signaturePolicyArgs(signaturePolicy string) []string {
+	if signaturePolicy != "" {
+		return []string{"--signature-policy", signaturePolicy}
+	}
+	return []string{}
+}
+
+func (c *AdminClient) execArgs(env []string, command []string) []string {
+	args := []string{"exec"}
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	args = append(args, c.ContainerName)
+	return append(args, command...)
+}
+
+func (c *AdminClient) run(env []string, command ...string) error {
+	return docker.RunDockerCommand(".", c.Verbose, c.Verbose, c.execArgs(env, command)...)
+}
+
+func (c *AdminClient) exec(env []string, command ...string) (string, error) {
+	return docker.RunDockerCommandBuffered(".", c.Verbose, c.execArgs(env, command)...)
+}
@@ -0,0 +1,88 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecArgs(T *testing.T) {
+	c := &AdminClient{ContainerName: "test_fabric_tools"}
+	peer := NewPeerContext("org1.example.com", "Org1MSP", "fabric_peer")
+	assert.Equal(T, []string{
+		"exec",
+		"-e", "CORE_PEER_ADDRESS=fabric_peer:7051",
+		"-e", "CORE_PEER_TLS_ENABLED=true",
+		"-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt",
+		"-e", "CORE_PEER_LOCALMSPID=Org1MSP",
+		"-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp",
+		"test_fabric_tools",
+		"peer", "channel", "join", "-b", "/etc/firefly/firefly.block",
+	}, c.execArgs(peer.env(), []string{"peer", "channel", "join", "-b", "/etc/firefly/firefly.block"}))
+
+	// Orderer admin commands don't act as a peer
+	assert.Equal(T, []string{"exec", "test_fabric_tools", "osnadmin", "channel", "list"}, c.execArgs(nil, []string{"osnadmin", "channel", "list"}))
+}
+
+func TestOrdererContext(T *testing.T) {
+	orderer := NewOrdererContext("example.com", "fabric_orderer2")
+	assert.Equal(T, "fabric_orderer2:7053", orderer.AdminAddress)
+	assert.Equal(T, "/etc/firefly/organizations/ordererOrganizations/example.com/users/Admin@example.com/tls/client.key", orderer.AdminClientKey)
+	assert.Equal(T, []string{
+		"-o", "fabric_orderer2:7050",
+		"--ordererTLSHostnameOverride", "fabric_orderer2",
+		"--tls",
+		"--cafile", "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer2.example.com/msp/tlscacerts/tlsca.example.com-cert.pem",
+	}, orderer.args())
+}
+
+func TestQueryStatusCode(T *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "not found",
+			err:      errors.New("docker exec test_fabric_tools peer lifecycle chaincode querycommitted\nFailed [1] Error: query failed with status: 404 - namespace firefly is not defined"),
+			expected: 404,
+		},
+		{
+			name:     "other status",
+			err:      errors.New("Failed [1] Error: query failed with status: 500 - failed to invoke backing implementation"),
+			expected: 500,
+		},
+		{
+			name:     "not a query failure",
+			err:      errors.New("Failed [1] Error: failed to retrieve endorser client: connection refused 404"),
+			expected: 0,
+		},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, queryStatusCode(tc.err))
+		})
+	}
+}
+
+func TestSignaturePolicyArgs(T *testing.T) {
+	assert.Equal(T, []string{}, signaturePolicyArgs(""))
+	assert.Equal(T, []string{"--signature-policy", "OR('Org1MSP.member')"}, signaturePolicyArgs("OR('Org1MSP.member')"))
+}
@@ -16,6 +16,18 @@
 
 package fabric
 
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
 type QueryInstalledResponse struct {
 	InstalledChaincodes []*InstalledChaincode `json:"installed_chaincodes"`
 }
@@ -24,3 +36,51 @@ type InstalledChaincode struct {
 	PackageID string `json:"package_id,omitempty"`
 	Label     string `json:"label,omitempty"`
 }
+
+type QueryCommittedResponse struct {
+	Sequence int    `json:"sequence"`
+	Version  string `json:"version"`
+}
+
+type ChaincodePackageMetadata struct {
+	Type  string `json:"type,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+// The package ID that a peer assigns to an installed chaincode package is the
+// package label, followed by the SHA-256 hash of the package file
+func getPackageID(packagePath string) (string, error) {
+	packageBytes, err := ioutil.ReadFile(packagePath)
+	if err != nil {
+		return "", err
+	}
+	metadata, err := readPackageMetadata(packageBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chaincode package '%s': %s", packagePath, err)
+	}
+	hash := sha256.Sum256(packageBytes)
+	return fmt.Sprintf("%s:%s", metadata.Label, hex.EncodeToString(hash[:])), nil
+}
+
+func readPackageMetadata(packageBytes []byte) (*ChaincodePackageMetadata, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(packageBytes))
+	if err != nil {
+		return nil, err
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("metadata.json not found")
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Name == "metadata.json" {
+			var metadata *ChaincodePackageMetadata
+			if err := json.NewDecoder(tarReader).Decode(&metadata); err != nil {
+				return nil, err
+			}
+			return metadata, nil
+		}
+	}
+}
@@ -58,16 +58,18 @@ type CryptogenConfig struct {
 	PeerOrgs    []*Org `yaml:"PeerOrgs,omitempty"`
 }
 
-func WriteCryptogenConfig(memberCount int, path string) error {
+func WriteCryptogenConfig(memberCount int, orderers []string, path string) error {
+	ordererSpecs := make([]*Spec, len(orderers))
+	for i, orderer := range orderers {
+		ordererSpecs[i] = &Spec{Hostname: orderer}
+	}
 	cryptogenConfig := &CryptogenConfig{
 		OrdererOrgs: []*Org{
 			{
 				Name:          "Orderer",
 				Domain:        "example.com",
 				EnableNodeOUs: true,
-				Specs: []*Spec{
-					{Hostname: "fabric_orderer"},
-				},
+				Specs:         ordererSpecs,
 			},
 		},
 		PeerOrgs: []*Org{
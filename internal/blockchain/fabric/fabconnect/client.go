@@ -27,82 +27,114 @@ import (
 )
 
 type CreateIdentityRequest struct {
-	Name string
-	Type string
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	MaxEnrollments int               `json:"maxEnrollments,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
 }
 
 type CreateIdentityResponse struct {
-	Name   string
-	Secret string
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
 }
 
 type EnrollIdentityRequest struct {
-	Secret string
+	Secret string `json:"secret"`
+	// Attributes to include in the enrollment certificate, mapped to whether each one is optional
+	Attributes map[string]bool `json:"attributes,omitempty"`
 }
 
 type EnrollIdentityResponse struct {
-	Name    string
-	Success string
+	Name    string `json:"name"`
+	Success string `json:"success"`
+}
+
+type Identity struct {
+	Name           string `json:"name"`
+	MSPID          string `json:"mspId"`
+	EnrollmentCert string `json:"enrollmentCert,omitempty"`
+	CACert         string `json:"caCert,omitempty"`
+}
+
+type RevokeIdentityRequest struct {
+	Reason string `json:"reason,omitempty"`
+	GenCRL bool   `json:"gencrl,omitempty"`
+}
+
+type RevokeIdentityResponse struct {
+	RevokedCerts []map[string]string `json:"revokedCerts"`
+	CRL          string              `json:"CRL,omitempty"`
 }
 
 func CreateIdentity(fabconnectUrl string, signer string) (*CreateIdentityResponse, error) {
-	u, err := url.Parse(fabconnectUrl)
-	if err != nil {
-		return nil, err
-	}
-	u, err = u.Parse(path.Join("identities"))
+	return RegisterIdentity(fabconnectUrl, &CreateIdentityRequest{Name: signer, Type: "client"})
+}
+
+func RegisterIdentity(fabconnectUrl string, request *CreateIdentityRequest) (*CreateIdentityResponse, error) {
+	responseBody, err := doRequest("POST", fabconnectUrl, path.Join("identities"), request)
 	if err != nil {
 		return nil, err
 	}
-	requestUrl := u.String()
-	requestBody, err := json.Marshal(&CreateIdentityRequest{Name: signer, Type: "client"})
+	var createIdentityResponse *CreateIdentityResponse
+	json.Unmarshal(responseBody, &createIdentityResponse)
+	return createIdentityResponse, nil
+}
+
+func EnrollIdentity(fabconnectUrl, signer, secret string) (*EnrollIdentityResponse, error) {
+	return EnrollIdentityWithAttributes(fabconnectUrl, signer, &EnrollIdentityRequest{Secret: secret})
+}
+
+func EnrollIdentityWithAttributes(fabconnectUrl, signer string, request *EnrollIdentityRequest) (*EnrollIdentityResponse, error) {
+	responseBody, err := doRequest("POST", fabconnectUrl, path.Join("identities", signer, "enroll"), request)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", requestUrl, bytes.NewBuffer(requestBody))
+	var enrollIdentityResponse *EnrollIdentityResponse
+	json.Unmarshal(responseBody, &enrollIdentityResponse)
+	return enrollIdentityResponse, nil
+}
+
+func ListIdentities(fabconnectUrl string) ([]*Identity, error) {
+	responseBody, err := doRequest("GET", fabconnectUrl, path.Join("identities"), nil)
 	if err != nil {
 		return nil, err
 	}
-	if err != nil {
+	var identities []*Identity
+	if err := json.Unmarshal(responseBody, &identities); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	return identities, nil
+}
+
+func RevokeIdentity(fabconnectUrl, signer string, request *RevokeIdentityRequest) (*RevokeIdentityResponse, error) {
+	responseBody, err := doRequest("POST", fabconnectUrl, path.Join("identities", signer, "revoke"), request)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	responseBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	var revokeIdentityResponse *RevokeIdentityResponse
+	if err := json.Unmarshal(responseBody, &revokeIdentityResponse); err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("%d %s", resp.StatusCode, responseBody)
-	}
-	var createIdentityResponseBody *CreateIdentityResponse
-	json.Unmarshal(responseBody, &createIdentityResponseBody)
-	return createIdentityResponseBody, nil
+	return revokeIdentityResponse, nil
 }
 
-func EnrollIdentity(fabconnectUrl, signer, secret string) (*EnrollIdentityResponse, error) {
+func doRequest(method, fabconnectUrl, requestPath string, body interface{}) ([]byte, error) {
 	u, err := url.Parse(fabconnectUrl)
 	if err != nil {
 		return nil, err
 	}
-	u, err = u.Parse(path.Join("identities", signer, "enroll"))
+	u, err = u.Parse(requestPath)
 	if err != nil {
 		return nil, err
 	}
 	requestUrl := u.String()
-	requestBody, err := json.Marshal(&EnrollIdentityRequest{Secret: secret})
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest("POST", requestUrl, bytes.NewBuffer(requestBody))
-	if err != nil {
-		return nil, err
+	var requestBody []byte
+	if body != nil {
+		if requestBody, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
 	}
+	req, err := http.NewRequest(method, requestUrl, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +152,5 @@ func EnrollIdentity(fabconnectUrl, signer, secret string) (*EnrollIdentityRespon
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("%d %s", resp.StatusCode, responseBody)
 	}
-	var enrollIdentityResponse *EnrollIdentityResponse
-	json.Unmarshal(responseBody, &enrollIdentityResponse)
-	return enrollIdentityResponse, nil
+	return responseBody, nil
 }
@@ -0,0 +1,114 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric/fabconnect"
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// getFabconnectServiceDefinitions returns a fabconnect service for each member. The crypto material referenced
+// by the connection profile is mounted at /etc/firefly, either from the stack's own network or from a local
+// directory when connecting to a remote network
+func getFabconnectServiceDefinitions(s *types.Stack, dependsOn map[string]map[string]string) []*docker.ServiceDefinition {
+	blockchainDirectory := path.Join(constants.StacksDir, s.Name, "blockchain")
+	serviceDefinitions := make([]*docker.ServiceDefinition, len(s.Members))
+	for i, member := range s.Members {
+		volumeNames := []string{
+			"fabconnect_receipts_" + member.ID,
+			"fabconnect_events_" + member.ID,
+		}
+		cryptoVolume := "firefly_fabric:/etc/firefly"
+		if s.Fabric != nil && s.Fabric.CryptoDir != "" {
+			cryptoVolume = fmt.Sprintf("%s:/etc/firefly", s.Fabric.CryptoDir)
+		} else {
+			volumeNames = append(volumeNames, "firefly_fabric")
+		}
+		serviceDefinitions[i] = &docker.ServiceDefinition{
+			ServiceName: "fabconnect_" + member.ID,
+			Service: &docker.Service{
+				Image:         "ghcr.io/hyperledger/firefly-fabconnect:latest",
+				ContainerName: fmt.Sprintf("%s_fabconnect_%s", s.Name, member.ID),
+				Command:       "-f /fabconnect/fabconnect.yaml",
+				DependsOn:     dependsOn,
+				Ports:         []string{fmt.Sprintf("%d:3000", member.ExposedConnectorPort)},
+				Volumes: []string{
+					fmt.Sprintf("fabconnect_receipts_%s:/fabconnect/receipts", member.ID),
+					fmt.Sprintf("fabconnect_events_%s:/fabconnect/events", member.ID),
+					fmt.Sprintf("%s:/fabconnect/fabconnect.yaml", path.Join(blockchainDirectory, "fabconnect.yaml")),
+					fmt.Sprintf("%s:/fabconnect/ccp.yaml", path.Join(blockchainDirectory, "ccp.yaml")),
+					cryptoVolume,
+				},
+				Logging: docker.StandardLogOptions,
+			},
+			VolumeNames: volumeNames,
+		}
+	}
+	return serviceDefinitions
+}
+
+func getFireflyConfig(s *types.Stack, m *types.Member) (blockchainConfig *core.BlockchainConfig, orgConfig *core.OrgConfig) {
+	orgConfig = &core.OrgConfig{
+		Name:     m.OrgName,
+		Identity: m.OrgName,
+	}
+
+	blockchainConfig = &core.BlockchainConfig{
+		Type: "fabric",
+		Fabric: &core.FabricConfig{
+			Fabconnect: &core.FabconnectConfig{
+				URL:       getFabconnectUrl(m),
+				Chaincode: s.Fabric.GetChaincodeName(),
+				Channel:   s.Fabric.GetChannel(),
+				Signer:    m.OrgName,
+				Topic:     m.ID,
+			},
+		},
+	}
+	return
+}
+
+func getFabconnectUrl(member *types.Member) string {
+	if !member.External {
+		return fmt.Sprintf("http://fabconnect_%s:3000", member.ID)
+	} else {
+		return fmt.Sprintf("http://127.0.0.1:%v", member.ExposedConnectorPort)
+	}
+}
+
+// registerIdentities registers and enrolls a signing identity for each member's org with the CA
+func registerIdentities(s *types.Stack, l log.Logger) error {
+	l.Info("registering identities")
+	for _, m := range s.Members {
+		res, err := fabconnect.CreateIdentity(fmt.Sprintf("http://127.0.0.1:%v", m.ExposedConnectorPort), m.OrgName)
+		if err != nil {
+			return err
+		}
+		_, err = fabconnect.EnrollIdentity(fmt.Sprintf("http://127.0.0.1:%v", m.ExposedConnectorPort), m.OrgName, res.Secret)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -51,52 +51,6 @@ func GenerateDockerServiceDefinitions(s *types.Stack) []*docker.ServiceDefinitio
 			VolumeNames: []string{"fabric_ca"},
 		},
 
-		// Fabric Orderer
-		{
-			ServiceName: "fabric_orderer",
-			Service: &docker.Service{
-				Image:         "hyperledger/fabric-orderer:2.3",
-				ContainerName: fmt.Sprintf("%s_fabric_orderer", s.Name),
-				Environment: map[string]string{
-					"FABRIC_LOGGING_SPEC":                       "INFO",
-					"ORDERER_GENERAL_LISTENADDRESS":             "0.0.0.0",
-					"ORDERER_GENERAL_LISTENPORT":                "7050",
-					"ORDERER_GENERAL_LOCALMSPID":                "OrdererMSP",
-					"ORDERER_GENERAL_LOCALMSPDIR":               "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/msp",
-					"ORDERER_GENERAL_TLS_ENABLED":               "true",
-					"ORDERER_GENERAL_TLS_PRIVATEKEY":            "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.key",
-					"ORDERER_GENERAL_TLS_CERTIFICATE":           "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.crt",
-					"ORDERER_GENERAL_TLS_ROOTCAS":               "[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/ca.crt]",
-					"ORDERER_KAFKA_TOPIC_REPLICATIONFACTOR":     "1",
-					"ORDERER_KAFKA_VERBOSE":                     "true",
-					"ORDERER_GENERAL_CLUSTER_CLIENTCERTIFICATE": "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.crt",
-					"ORDERER_GENERAL_CLUSTER_CLIENTPRIVATEKEY":  "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.key",
-					"ORDERER_GENERAL_CLUSTER_ROOTCAS":           "[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/ca.crt]",
-					"ORDERER_GENERAL_BOOTSTRAPMETHOD":           "none",
-					"ORDERER_CHANNELPARTICIPATION_ENABLED":      "true",
-					"ORDERER_ADMIN_TLS_ENABLED":                 "true",
-					"ORDERER_ADMIN_TLS_CERTIFICATE":             "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.crt",
-					"ORDERER_ADMIN_TLS_PRIVATEKEY":              "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/server.key",
-					"ORDERER_ADMIN_TLS_ROOTCAS":                 "[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/ca.crt]",
-					"ORDERER_ADMIN_TLS_CLIENTROOTCAS":           "[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/tls/ca.crt]",
-					"ORDERER_ADMIN_LISTENADDRESS":               "0.0.0.0:7053",
-					"ORDERER_OPERATIONS_LISTENADDRESS":          "0.0.0.0:17050",
-				},
-				WorkingDir: "/opt/gopath/src/github.com/hyperledger/fabric",
-				Command:    "orderer",
-				Volumes: []string{
-					"firefly_fabric:/etc/firefly",
-					"fabric_orderer:/var/hyperledger/production/orderer",
-				},
-				Ports: []string{
-					"7050:7050",
-					"7053:7053",
-					"17050:17050",
-				},
-			},
-			VolumeNames: []string{"fabric_orderer"},
-		},
-
 		// Fabric Peer
 		{
 			ServiceName: "fabric_peer",
@@ -122,6 +76,8 @@ func GenerateDockerServiceDefinitions(s *types.Stack) []*docker.ServiceDefinitio
 					"CORE_PEER_GOSSIP_EXTERNALENDPOINT":     "fabric_peer:7051",
 					"CORE_PEER_LOCALMSPID":                  "Org1MSP",
 					"CORE_OPERATIONS_LISTENADDRESS":         "0.0.0.0:17051",
+					"CORE_CHAINCODE_BUILDER":                FabricCCEnvImageName,
+					"CORE_CHAINCODE_GOLANG_RUNTIME":         FabricBaseOSImageName,
 				},
 				Volumes: []string{
 					"firefly_fabric:/etc/firefly",
@@ -136,5 +92,69 @@ func GenerateDockerServiceDefinitions(s *types.Stack) []*docker.ServiceDefinitio
 			VolumeNames: []string{"fabric_peer"},
 		},
 	}
+	for i, name := range GetOrdererNames(s) {
+		serviceDefinitions = append(serviceDefinitions, getOrdererServiceDefinition(s, name, i))
+	}
 	return serviceDefinitions
 }
+
+// Orderers are named fabric_orderer, fabric_orderer2, fabric_orderer3 and so on, so that
+// stacks with a single orderer are unchanged. Each orderer's host ports are offset by 100
+func GetOrdererNames(s *types.Stack) []string {
+	names := make([]string, s.Fabric.GetOrderers())
+	for i := range names {
+		names[i] = "fabric_orderer"
+		if i > 0 {
+			names[i] += fmt.Sprint(i + 1)
+		}
+	}
+	return names
+}
+
+func getOrdererServiceDefinition(s *types.Stack, name string, index int) *docker.ServiceDefinition {
+	portOffset := index * 100
+	return &docker.ServiceDefinition{
+		ServiceName: name,
+		Service: &docker.Service{
+			Image:         "hyperledger/fabric-orderer:2.3",
+			ContainerName: fmt.Sprintf("%s_%s", s.Name, name),
+			Environment: map[string]string{
+				"FABRIC_LOGGING_SPEC":                       "INFO",
+				"ORDERER_GENERAL_LISTENADDRESS":             "0.0.0.0",
+				"ORDERER_GENERAL_LISTENPORT":                "7050",
+				"ORDERER_GENERAL_LOCALMSPID":                "OrdererMSP",
+				"ORDERER_GENERAL_LOCALMSPDIR":               fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/msp", name),
+				"ORDERER_GENERAL_TLS_ENABLED":               "true",
+				"ORDERER_GENERAL_TLS_PRIVATEKEY":            fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.key", name),
+				"ORDERER_GENERAL_TLS_CERTIFICATE":           fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.crt", name),
+				"ORDERER_GENERAL_TLS_ROOTCAS":               fmt.Sprintf("[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/ca.crt]", name),
+				"ORDERER_KAFKA_TOPIC_REPLICATIONFACTOR":     "1",
+				"ORDERER_KAFKA_VERBOSE":                     "true",
+				"ORDERER_GENERAL_CLUSTER_CLIENTCERTIFICATE": fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.crt", name),
+				"ORDERER_GENERAL_CLUSTER_CLIENTPRIVATEKEY":  fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.key", name),
+				"ORDERER_GENERAL_CLUSTER_ROOTCAS":           fmt.Sprintf("[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/ca.crt]", name),
+				"ORDERER_GENERAL_BOOTSTRAPMETHOD":           "none",
+				"ORDERER_CHANNELPARTICIPATION_ENABLED":      "true",
+				"ORDERER_ADMIN_TLS_ENABLED":                 "true",
+				"ORDERER_ADMIN_TLS_CERTIFICATE":             fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.crt", name),
+				"ORDERER_ADMIN_TLS_PRIVATEKEY":              fmt.Sprintf("/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/server.key", name),
+				"ORDERER_ADMIN_TLS_ROOTCAS":                 fmt.Sprintf("[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/ca.crt]", name),
+				"ORDERER_ADMIN_TLS_CLIENTROOTCAS":           fmt.Sprintf("[/etc/firefly/organizations/ordererOrganizations/example.com/orderers/%s.example.com/tls/ca.crt]", name),
+				"ORDERER_ADMIN_LISTENADDRESS":               "0.0.0.0:7053",
+				"ORDERER_OPERATIONS_LISTENADDRESS":          "0.0.0.0:17050",
+			},
+			WorkingDir: "/opt/gopath/src/github.com/hyperledger/fabric",
+			Command:    "orderer",
+			Volumes: []string{
+				"firefly_fabric:/etc/firefly",
+				name + ":/var/hyperledger/production/orderer",
+			},
+			Ports: []string{
+				fmt.Sprintf("%d:7050", 7050+portOffset),
+				fmt.Sprintf("%d:7053", 7053+portOffset),
+				fmt.Sprintf("%d:17050", 17050+portOffset),
+			},
+		},
+		VolumeNames: []string{name},
+	}
+}
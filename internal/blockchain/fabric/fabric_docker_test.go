@@ -0,0 +1,40 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrdererNames(T *testing.T) {
+	// Stacks created before the orderer count was configurable have one orderer
+	assert.Equal(T, []string{"fabric_orderer"}, GetOrdererNames(&types.Stack{}))
+	stack := &types.Stack{Fabric: &types.FabricOptions{Orderers: 3}}
+	assert.Equal(T, []string{"fabric_orderer", "fabric_orderer2", "fabric_orderer3"}, GetOrdererNames(stack))
+}
+
+func TestGetOrdererServiceDefinition(T *testing.T) {
+	stack := &types.Stack{Name: "test", Fabric: &types.FabricOptions{Orderers: 3}}
+	serviceDefinition := getOrdererServiceDefinition(stack, "fabric_orderer3", 2)
+	assert.Equal(T, "fabric_orderer3", serviceDefinition.ServiceName)
+	assert.Equal(T, "test_fabric_orderer3", serviceDefinition.Service.ContainerName)
+	assert.Equal(T, []string{"7250:7050", "7253:7053", "17250:17050"}, serviceDefinition.Service.Ports)
+	assert.Equal(T, []string{"fabric_orderer3"}, serviceDefinition.VolumeNames)
+}
@@ -18,12 +18,16 @@ package fabric
 
 import (
 	_ "embed"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric/fabconnect"
 	"github.com/hyperledger/firefly-cli/internal/constants"
@@ -31,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly-cli/internal/docker"
 	"github.com/hyperledger/firefly-cli/internal/log"
 	"github.com/hyperledger/firefly-cli/pkg/types"
+	"gopkg.in/yaml.v2"
 )
 
 type FabricProvider struct {
@@ -46,10 +51,10 @@ func (p *FabricProvider) WriteConfig() error {
 	blockchainDirectory := path.Join(constants.StacksDir, p.Stack.Name, "blockchain")
 	cryptogenYamlPath := path.Join(blockchainDirectory, "cryptogen.yaml")
 
-	if err := WriteCryptogenConfig(len(p.Stack.Members), cryptogenYamlPath); err != nil {
+	if err := WriteCryptogenConfig(len(p.Stack.Members), GetOrdererNames(p.Stack), cryptogenYamlPath); err != nil {
 		return err
 	}
-	if err := WriteNetworkConfig(path.Join(blockchainDirectory, "ccp.yaml")); err != nil {
+	if err := WriteNetworkConfig(path.Join(blockchainDirectory, "ccp.yaml"), p.Stack.Fabric.GetChannels(), GetOrdererNames(p.Stack)); err != nil {
 		return err
 	}
 	if err := fabconnect.WriteFabconnectConfig(path.Join(blockchainDirectory, "fabconnect.yaml")); err != nil {
@@ -72,13 +77,15 @@ func (p *FabricProvider) FirstTimeSetup() error {
 	}
 
 	// Run cryptogen to generate MSP
-	if err := docker.RunDockerCommand(blockchainDirectory, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/etc/template.yml", cryptogenYamlPath), "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "cryptogen", "generate", "--config", "/etc/template.yml", "--output", "/etc/firefly/organizations"); err != nil {
+	if err := docker.RunDockerCommand(blockchainDirectory, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/etc/template.yml", cryptogenYamlPath), "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), FabricToolsImageName, "cryptogen", "generate", "--config", "/etc/template.yml", "--output", "/etc/firefly/organizations"); err != nil {
 		return err
 	}
 
-	// Generate genesis block
-	if err := docker.RunDockerCommand(blockchainDirectory, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "-v", fmt.Sprintf("%s:/etc/hyperledger/fabric/configtx.yaml", path.Join(blockchainDirectory, "configtx.yaml")), "hyperledger/fabric-tools:2.3", "configtxgen", "-outputBlock", "/etc/firefly/firefly.block", "-profile", "SingleOrgApplicationGenesis", "-channelID", "firefly"); err != nil {
-		return err
+	// Generate the genesis block for each channel
+	for _, channel := range p.Stack.Fabric.GetChannels() {
+		if err := docker.RunDockerCommand(blockchainDirectory, p.Verbose, p.Verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "-v", fmt.Sprintf("%s:/etc/hyperledger/fabric/configtx.yaml", path.Join(blockchainDirectory, "configtx.yaml")), FabricToolsImageName, "configtxgen", "-outputBlock", fmt.Sprintf("/etc/firefly/%s.block", channel), "-profile", p.Stack.Fabric.GetGenesisProfile(), "-channelID", channel); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -89,39 +96,120 @@ func (p *FabricProvider) DeploySmartContracts() error {
 		return err
 	}
 
-	if err := p.createChannel(); err != nil {
+	client, err := p.startAdminClient()
+	if err != nil {
 		return err
 	}
+	defer client.Stop()
 
-	if err := p.joinChannel(); err != nil {
+	for _, channel := range p.Stack.Fabric.GetChannels() {
+		if err := p.createChannel(client, channel); err != nil {
+			return err
+		}
+
+		if err := p.joinChannel(client, channel); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.deployChaincode(client, "firefly_fabric.tar.gz", p.Stack.Fabric.GetChaincodeName(), p.Stack.Fabric.GetChannel()); err != nil {
 		return err
 	}
 
-	if err := p.installChaincode(); err != nil {
+	if err := registerIdentities(p.Stack, p.Log); err != nil {
 		return err
 	}
 
-	res, err := p.queryInstalled()
+	return nil
+}
+
+func (p *FabricProvider) UpgradeSmartContracts() error {
+	if err := p.extractChaincode(); err != nil {
+		return err
+	}
+	client, err := p.startAdminClient()
 	if err != nil {
 		return err
 	}
-	if len(res.InstalledChaincodes) == 0 {
-		return fmt.Errorf("failed to find installed chaincode")
+	defer client.Stop()
+	_, err = p.deployChaincode(client, "firefly_fabric.tar.gz", p.Stack.Fabric.GetChaincodeName(), p.Stack.Fabric.GetChannel())
+	return err
+}
+
+func (p *FabricProvider) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	if len(options.Args) > 0 {
+		return "", errors.New("arguments are not supported when deploying chaincode")
+	}
+	channel, err := getDeployChannel(p.Stack, options.Channel)
+	if err != nil {
+		return "", err
+	}
+	packageID, err := getPackageID(filename)
+	if err != nil {
+		return "", err
+	}
+	name := options.Name
+	if name == "" {
+		name = strings.Split(packageID, ":")[0]
 	}
 
-	if err := p.approveChaincode(res.InstalledChaincodes[0].PackageID); err != nil {
-		return err
+	// Copy the package into the stack's contracts directory so it is available in the fabric-tools container. Packages
+	// are kept in their own directory, so they can't overwrite the FireFly chaincode package
+	chaincodeDir := path.Join(constants.StacksDir, p.Stack.Name, "contracts", "chaincode")
+	if err := os.MkdirAll(chaincodeDir, 0755); err != nil {
+		return "", err
+	}
+	packageBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	packageFilename := path.Join("chaincode", filepath.Base(filename))
+	if err := ioutil.WriteFile(path.Join(chaincodeDir, filepath.Base(filename)), packageBytes, 0755); err != nil {
+		return "", err
 	}
 
-	if err := p.commitChaincode(); err != nil {
-		return err
+	client, err := p.startAdminClient()
+	if err != nil {
+		return "", err
 	}
+	defer client.Stop()
+	if _, err := p.deployChaincode(client, packageFilename, name, channel); err != nil {
+		return "", err
+	}
+	return name, nil
+}
 
-	if err := p.registerIdentities(); err != nil {
-		return err
+// GetConfigtxProfiles returns the names of the profiles in a configtx.yaml file
+func GetConfigtxProfiles(filename string) ([]string, error) {
+	d, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var configtx struct {
+		Profiles map[string]interface{} `yaml:"Profiles"`
+	}
+	if err := yaml.Unmarshal(d, &configtx); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %s", filename, err)
+	}
+	profiles := make([]string, 0, len(configtx.Profiles))
+	for name := range configtx.Profiles {
+		profiles = append(profiles, name)
 	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
 
-	return nil
+// getDeployChannel returns the channel to deploy chaincode on, which defaults to the channel FireFly uses
+func getDeployChannel(s *types.Stack, channel string) (string, error) {
+	if channel == "" {
+		return s.Fabric.GetChannel(), nil
+	}
+	for _, c := range s.Fabric.GetChannels() {
+		if c == channel {
+			return channel, nil
+		}
+	}
+	return "", fmt.Errorf("the stack is not joined to channel '%s' - please use one of: %s", channel, strings.Join(s.Fabric.GetChannels(), ", "))
 }
 
 func (p *FabricProvider) PreStart() error {
@@ -134,95 +222,99 @@ func (p *FabricProvider) PostStart() error {
 
 func (p *FabricProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
 	serviceDefinitions := GenerateDockerServiceDefinitions(p.Stack)
-	serviceDefinitions = append(serviceDefinitions, p.getFabconnectServiceDefinitions(p.Stack.Members)...)
+	dependsOn := map[string]map[string]string{
+		"fabric_ca":   {"condition": "service_started"},
+		"fabric_peer": {"condition": "service_started"},
+	}
+	for _, orderer := range GetOrdererNames(p.Stack) {
+		dependsOn[orderer] = map[string]string{"condition": "service_started"}
+	}
+	serviceDefinitions = append(serviceDefinitions, getFabconnectServiceDefinitions(p.Stack, dependsOn)...)
 	return serviceDefinitions
 }
 
 func (p *FabricProvider) GetFireflyConfig(m *types.Member) (blockchainConfig *core.BlockchainConfig, orgConfig *core.OrgConfig) {
-	orgConfig = &core.OrgConfig{
-		Name:     m.OrgName,
-		Identity: m.OrgName,
-	}
-
-	blockchainConfig = &core.BlockchainConfig{
-		Type: "fabric",
-		Fabric: &core.FabricConfig{
-			Fabconnect: &core.FabconnectConfig{
-				URL:       p.getFabconnectUrl(m),
-				Chaincode: "firefly",
-				Channel:   "firefly",
-				Signer:    m.OrgName,
-				Topic:     m.ID,
-			},
-		},
-	}
-	return
+	return getFireflyConfig(p.Stack, m)
 }
 
 func (p *FabricProvider) Reset() error {
 	return nil
 }
 
-func (p *FabricProvider) getFabconnectServiceDefinitions(members []*types.Member) []*docker.ServiceDefinition {
-	blockchainDirectory := path.Join(constants.StacksDir, p.Stack.Name, "blockchain")
-	serviceDefinitions := make([]*docker.ServiceDefinition, len(members))
-	for i, member := range members {
-		serviceDefinitions[i] = &docker.ServiceDefinition{
-			ServiceName: "fabconnect_" + member.ID,
-			Service: &docker.Service{
-				Image:         "ghcr.io/hyperledger/firefly-fabconnect:latest",
-				ContainerName: fmt.Sprintf("%s_fabconnect_%s", p.Stack.Name, member.ID),
-				Command:       "-f /fabconnect/fabconnect.yaml",
-				DependsOn: map[string]map[string]string{
-					"fabric_ca":      {"condition": "service_started"},
-					"fabric_peer":    {"condition": "service_started"},
-					"fabric_orderer": {"condition": "service_started"},
-				},
-				Ports: []string{fmt.Sprintf("%d:3000", member.ExposedConnectorPort)},
-				Volumes: []string{
-					fmt.Sprintf("fabconnect_receipts_%s:/fabconnect/receipts", member.ID),
-					fmt.Sprintf("fabconnect_events_%s:/fabconnect/events", member.ID),
-					fmt.Sprintf("%s:/fabconnect/fabconnect.yaml", path.Join(blockchainDirectory, "fabconnect.yaml")),
-					fmt.Sprintf("%s:/fabconnect/ccp.yaml", path.Join(blockchainDirectory, "ccp.yaml")),
-					"firefly_fabric:/etc/firefly",
-				},
-				Logging: docker.StandardLogOptions,
-			},
-			VolumeNames: []string{
-				"fabconnect_receipts_" + member.ID,
-				"fabconnect_events_" + member.ID,
-				"firefly_fabric",
-			},
+func (p *FabricProvider) writeConfigtxYaml() error {
+	filePath := path.Join(constants.StacksDir, p.Stack.Name, "blockchain", "configtx.yaml")
+	// A configtx.yaml given at init is used as it is
+	if p.Stack.Fabric != nil && p.Stack.Fabric.Configtx != "" {
+		configtx, err := ioutil.ReadFile(p.Stack.Fabric.Configtx)
+		if err != nil {
+			return err
 		}
+		return ioutil.WriteFile(filePath, configtx, 0755)
 	}
-	return serviceDefinitions
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return renderConfigtxYaml(p.Stack, f)
 }
 
-func (p *FabricProvider) getFabconnectUrl(member *types.Member) string {
-	if !member.External {
-		return fmt.Sprintf("http://fabconnect_%s:3000", member.ID)
-	} else {
-		return fmt.Sprintf("http://127.0.0.1:%v", member.ExposedConnectorPort)
+// renderConfigtxYaml writes the configtx.yaml for the stack, with every orderer as an etcdraft consenter
+func renderConfigtxYaml(s *types.Stack, w io.Writer) error {
+	t, err := template.New("configtx").Parse(configtxYaml)
+	if err != nil {
+		return err
 	}
+	return t.Execute(w, map[string]interface{}{
+		"Orderers": GetOrdererNames(s),
+	})
 }
 
-func (p *FabricProvider) writeConfigtxYaml() error {
-	filePath := path.Join(constants.StacksDir, p.Stack.Name, "blockchain", "configtx.yaml")
-	return ioutil.WriteFile(filePath, []byte(configtxYaml), 0755)
+func (p *FabricProvider) startAdminClient() (*AdminClient, error) {
+	p.Log.Info("starting fabric admin client")
+	return StartAdminClient(p.Stack.Name, p.Verbose)
 }
 
-func (p *FabricProvider) createChannel() error {
-	p.Log.Info("creating channel")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	return docker.RunDockerCommand(stackDir, p.Verbose, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "osnadmin", "channel", "join", "--channelID", "firefly", "--config-block", "/etc/firefly/firefly.block", "-o", "fabric_orderer:7053", "--ca-file", "/etc/firefly/organizations/ordererOrganizations/example.com/users/Admin@example.com/tls/ca.crt", "--client-cert", "/etc/firefly/organizations/ordererOrganizations/example.com/users/Admin@example.com/tls/client.crt", "--client-key", "/etc/firefly/organizations/ordererOrganizations/example.com/users/Admin@example.com/tls/client.key")
+// All admin operations currently act as the admin of the single peer org
+func (p *FabricProvider) getPeerContext() *PeerContext {
+	return NewPeerContext("org1.example.com", "Org1MSP", "fabric_peer")
 }
 
-func (p *FabricProvider) joinChannel() error {
-	p.Log.Info("joining channel")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	return docker.RunDockerCommand(stackDir, p.Verbose, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "-e", "CORE_PEER_ADDRESS=fabric_peer:7051", "-e", "CORE_PEER_TLS_ENABLED=true", "-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt", "-e", "CORE_PEER_LOCALMSPID=Org1MSP", "-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp", "hyperledger/fabric-tools:2.3", "peer", "channel", "join", "-b", "/etc/firefly/firefly.block")
+func (p *FabricProvider) getOrdererContexts() []*OrdererContext {
+	names := GetOrdererNames(p.Stack)
+	orderers := make([]*OrdererContext, len(names))
+	for i, name := range names {
+		orderers[i] = NewOrdererContext("example.com", name)
+	}
+	return orderers
+}
+
+// withOrderer runs an operation against each orderer in turn until it succeeds, so that chaincode can still be
+// deployed while some of the orderers in the etcdraft cluster are stopped
+func (p *FabricProvider) withOrderer(operation func(orderer *OrdererContext) error) (err error) {
+	for _, orderer := range p.getOrdererContexts() {
+		if err = operation(orderer); err == nil {
+			return nil
+		}
+		p.Log.Info(fmt.Sprintf("orderer '%s' failed - trying the next orderer", orderer.TLSHostname))
+	}
+	return err
+}
+
+// createChannel joins every orderer in the etcdraft cluster to the channel using the channel participation API
+func (p *FabricProvider) createChannel(client *AdminClient, channel string) error {
+	p.Log.Info(fmt.Sprintf("creating channel '%s'", channel))
+	for _, orderer := range p.getOrdererContexts() {
+		if err := client.OrdererJoinChannel(orderer, channel, fmt.Sprintf("/etc/firefly/%s.block", channel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *FabricProvider) joinChannel(client *AdminClient, channel string) error {
+	p.Log.Info(fmt.Sprintf("joining channel '%s'", channel))
+	return client.JoinChannel(p.getPeerContext(), fmt.Sprintf("/etc/firefly/%s.block", channel))
 }
 
 func (p *FabricProvider) extractChaincode() error {
@@ -250,54 +342,99 @@ func (p *FabricProvider) extractChaincode() error {
 	return nil
 }
 
-func (p *FabricProvider) installChaincode() error {
-	p.Log.Info("installing chaincode")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	return docker.RunDockerCommand(stackDir, p.Verbose, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-e", "CORE_PEER_ADDRESS=fabric_peer:7051", "-e", "CORE_PEER_TLS_ENABLED=true", "-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt", "-e", "CORE_PEER_LOCALMSPID=Org1MSP", "-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp", "-v", fmt.Sprintf("%s:/contracts", path.Join(stackDir, "contracts")), "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "peer", "lifecycle", "chaincode", "install", "/contracts/firefly_fabric.tar.gz")
-}
+// deployChaincode installs, approves and commits a chaincode package. If a chaincode with the same name
+// is already committed on the channel, it is upgraded by committing the package with the next sequence number
+func (p *FabricProvider) deployChaincode(client *AdminClient, packageFilename, name, channel string) (*types.ChaincodeDeployment, error) {
+	packageID, err := getPackageID(path.Join(constants.StacksDir, p.Stack.Name, "contracts", packageFilename))
+	if err != nil {
+		return nil, err
+	}
 
-func (p *FabricProvider) queryInstalled() (*QueryInstalledResponse, error) {
-	p.Log.Info("querying installed chaincode")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	str, err := docker.RunDockerCommandBuffered(stackDir, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-e", "CORE_PEER_ADDRESS=fabric_peer:7051", "-e", "CORE_PEER_TLS_ENABLED=true", "-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt", "-e", "CORE_PEER_LOCALMSPID=Org1MSP", "-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp", "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "peer", "lifecycle", "chaincode", "queryinstalled", "--output", "json")
+	deployment := p.getChaincodeDeployment(name, channel)
+	if deployment != nil && deployment.PackageID == packageID {
+		p.Log.Info(fmt.Sprintf("chaincode '%s' is already up to date", name))
+		return deployment, nil
+	}
+
+	peer := p.getPeerContext()
+
+	p.Log.Info("querying committed chaincode")
+	committed, err := client.QueryCommitted(peer, channel, name)
 	if err != nil {
 		return nil, err
 	}
-	var res *QueryInstalledResponse
-	err = json.Unmarshal([]byte(str), &res)
+	sequence, version := getNextChaincodeVersion(committed)
+
+	installed, err := p.isInstalled(client, peer, packageID)
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
-}
+	if !installed {
+		p.Log.Info("installing chaincode")
+		if err := client.InstallChaincode(peer, path.Join("/contracts", packageFilename)); err != nil {
+			return nil, err
+		}
+		if installed, err = p.isInstalled(client, peer, packageID); err != nil {
+			return nil, err
+		} else if !installed {
+			return nil, fmt.Errorf("failed to find installed chaincode '%s'", packageID)
+		}
+	}
 
-func (p *FabricProvider) approveChaincode(packageId string) error {
 	p.Log.Info("approving chaincode")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	return docker.RunDockerCommand(stackDir, p.Verbose, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-e", "CORE_PEER_ADDRESS=fabric_peer:7051", "-e", "CORE_PEER_TLS_ENABLED=true", "-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt", "-e", "CORE_PEER_LOCALMSPID=Org1MSP", "-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp", "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "peer", "lifecycle", "chaincode", "approveformyorg", "-o", "fabric_orderer:7050", "--ordererTLSHostnameOverride", "fabric_orderer", "--channelID", "firefly", "--name", "firefly", "--version", "1.0", "--package-id", packageId, "--sequence", "1", "--tls", "--cafile", "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/msp/tlscacerts/tlsca.example.com-cert.pem")
-}
+	if err := p.withOrderer(func(orderer *OrdererContext) error {
+		return client.ApproveChaincode(peer, orderer, channel, name, version, packageID, sequence, p.Stack.Fabric.GetEndorsementPolicy())
+	}); err != nil {
+		return nil, err
+	}
 
-func (p *FabricProvider) commitChaincode() error {
 	p.Log.Info("committing chaincode")
-	stackDir := path.Join(constants.StacksDir, p.Stack.Name)
-	volumeName := fmt.Sprintf("%s_firefly_fabric", p.Stack.Name)
-	return docker.RunDockerCommand(stackDir, p.Verbose, p.Verbose, "run", "--rm", fmt.Sprintf("--network=%s_default", p.Stack.Name), "-e", "CORE_PEER_ADDRESS=fabric_peer:7051", "-e", "CORE_PEER_TLS_ENABLED=true", "-e", "CORE_PEER_TLS_ROOTCERT_FILE=/etc/firefly/organizations/peerOrganizations/org1.example.com/peers/fabric_peer.org1.example.com/tls/ca.crt", "-e", "CORE_PEER_LOCALMSPID=Org1MSP", "-e", "CORE_PEER_MSPCONFIGPATH=/etc/firefly/organizations/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp", "-v", fmt.Sprintf("%s:/etc/firefly", volumeName), "hyperledger/fabric-tools:2.3", "peer", "lifecycle", "chaincode", "commit", "-o", "fabric_orderer:7050", "--ordererTLSHostnameOverride", "fabric_orderer", "--channelID", "firefly", "--name", "firefly", "--version", "1.0", "--sequence", "1", "--tls", "--cafile", "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/fabric_orderer.example.com/msp/tlscacerts/tlsca.example.com-cert.pem")
+	if err := p.withOrderer(func(orderer *OrdererContext) error {
+		return client.CommitChaincode(peer, orderer, channel, name, version, sequence, p.Stack.Fabric.GetEndorsementPolicy())
+	}); err != nil {
+		return nil, err
+	}
+
+	if deployment == nil {
+		deployment = &types.ChaincodeDeployment{Name: name, Channel: channel}
+		p.Stack.Chaincodes = append(p.Stack.Chaincodes, deployment)
+	}
+	deployment.Filename = packageFilename
+	deployment.Version = version
+	deployment.Sequence = sequence
+	deployment.PackageID = packageID
+	return deployment, nil
 }
 
-func (p *FabricProvider) registerIdentities() error {
-	p.Log.Info("registering identities")
-	for _, m := range p.Stack.Members {
-		res, err := fabconnect.CreateIdentity(fmt.Sprintf("http://127.0.0.1:%v", m.ExposedConnectorPort), m.OrgName)
-		if err != nil {
-			return err
-		}
-		_, err = fabconnect.EnrollIdentity(fmt.Sprintf("http://127.0.0.1:%v", m.ExposedConnectorPort), m.OrgName, res.Secret)
-		if err != nil {
-			return err
+// getNextChaincodeVersion returns the sequence and version to commit a chaincode with. A chaincode that is
+// already committed is upgraded by committing it with the next sequence number
+func getNextChaincodeVersion(committed *QueryCommittedResponse) (int, string) {
+	sequence := 1
+	if committed != nil {
+		sequence = committed.Sequence + 1
+	}
+	return sequence, fmt.Sprintf("%d.0", sequence)
+}
+
+func (p *FabricProvider) getChaincodeDeployment(name, channel string) *types.ChaincodeDeployment {
+	for _, deployment := range p.Stack.Chaincodes {
+		if deployment.Name == name && deployment.Channel == channel {
+			return deployment
 		}
 	}
 	return nil
 }
+
+func (p *FabricProvider) isInstalled(client *AdminClient, peer *PeerContext, packageID string) (bool, error) {
+	p.Log.Info("querying installed chaincode")
+	res, err := client.QueryInstalled(peer)
+	if err != nil {
+		return false, err
+	}
+	for _, chaincode := range res.InstalledChaincodes {
+		if chaincode.PackageID == packageID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
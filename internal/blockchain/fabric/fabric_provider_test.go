@@ -0,0 +1,135 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGetDeployChannel(T *testing.T) {
+	stack := &types.Stack{Fabric: &types.FabricOptions{Channels: []string{"firefly", "assets"}}}
+	channel, err := getDeployChannel(stack, "")
+	assert.NoError(T, err)
+	assert.Equal(T, "firefly", channel)
+
+	channel, err = getDeployChannel(stack, "assets")
+	assert.NoError(T, err)
+	assert.Equal(T, "assets", channel)
+
+	_, err = getDeployChannel(stack, "other")
+	assert.EqualError(T, err, "the stack is not joined to channel 'other' - please use one of: firefly, assets")
+
+	// Stacks created before channels were configurable only have the firefly channel
+	channel, err = getDeployChannel(&types.Stack{}, "")
+	assert.NoError(T, err)
+	assert.Equal(T, "firefly", channel)
+}
+
+func TestGetConfigtxProfiles(T *testing.T) {
+	configtxPath := filepath.Join(T.TempDir(), "configtx.yaml")
+	assert.NoError(T, ioutil.WriteFile(configtxPath, []byte("Profiles:\n  TwoOrgs:\n    Orderer: {}\n  SingleOrgApplicationGenesis:\n    Orderer: {}\n"), 0644))
+	profiles, err := GetConfigtxProfiles(configtxPath)
+	assert.NoError(T, err)
+	assert.Equal(T, []string{"SingleOrgApplicationGenesis", "TwoOrgs"}, profiles)
+
+	assert.NoError(T, ioutil.WriteFile(configtxPath, []byte("Profiles: ["), 0644))
+	_, err = GetConfigtxProfiles(configtxPath)
+	assert.Error(T, err)
+}
+
+func TestRenderConfigtxYaml(T *testing.T) {
+	stack := &types.Stack{Fabric: &types.FabricOptions{Orderers: 3}}
+	var b strings.Builder
+	assert.NoError(T, renderConfigtxYaml(stack, &b))
+
+	var configtx struct {
+		Orderer struct {
+			Addresses []string `yaml:"Addresses"`
+			EtcdRaft  struct {
+				Consenters []struct {
+					Host          string `yaml:"Host"`
+					Port          int    `yaml:"Port"`
+					ClientTLSCert string `yaml:"ClientTLSCert"`
+				} `yaml:"Consenters"`
+			} `yaml:"EtcdRaft"`
+		} `yaml:"Orderer"`
+		Profiles map[string]interface{} `yaml:"Profiles"`
+	}
+	assert.NoError(T, yaml.Unmarshal([]byte(b.String()), &configtx))
+	assert.Equal(T, []string{"fabric_orderer:7050", "fabric_orderer2:7050", "fabric_orderer3:7050"}, configtx.Orderer.Addresses)
+	assert.Len(T, configtx.Orderer.EtcdRaft.Consenters, 3)
+	for i, name := range GetOrdererNames(stack) {
+		consenter := configtx.Orderer.EtcdRaft.Consenters[i]
+		assert.Equal(T, name, consenter.Host)
+		assert.Equal(T, 7050, consenter.Port)
+		assert.Equal(T, "/etc/firefly/organizations/ordererOrganizations/example.com/orderers/"+name+".example.com/tls/server.crt", consenter.ClientTLSCert)
+	}
+	assert.Contains(T, configtx.Profiles, types.DefaultGenesisProfile)
+}
+
+func TestWithOrderer(T *testing.T) {
+	p := &FabricProvider{
+		Log:   &log.StdoutLogger{LogLevel: log.Error},
+		Stack: &types.Stack{Fabric: &types.FabricOptions{Orderers: 3}},
+	}
+
+	// The first orderer is stopped
+	tried := []string{}
+	err := p.withOrderer(func(orderer *OrdererContext) error {
+		tried = append(tried, orderer.TLSHostname)
+		if orderer.TLSHostname == "fabric_orderer" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.NoError(T, err)
+	assert.Equal(T, []string{"fabric_orderer", "fabric_orderer2"}, tried)
+
+	// Every orderer is stopped
+	err = p.withOrderer(func(orderer *OrdererContext) error {
+		return errors.New(orderer.TLSHostname + " connection refused")
+	})
+	assert.EqualError(T, err, "fabric_orderer3 connection refused")
+}
+
+func TestGetNextChaincodeVersion(T *testing.T) {
+	sequence, version := getNextChaincodeVersion(nil)
+	assert.Equal(T, 1, sequence)
+	assert.Equal(T, "1.0", version)
+
+	sequence, version = getNextChaincodeVersion(&QueryCommittedResponse{Sequence: 2, Version: "2.0"})
+	assert.Equal(T, 3, sequence)
+	assert.Equal(T, "3.0", version)
+}
+
+func TestGetChaincodeDeployment(T *testing.T) {
+	p := &FabricProvider{Stack: &types.Stack{Chaincodes: []*types.ChaincodeDeployment{
+		{Name: "asset", Channel: "firefly", Sequence: 1},
+		{Name: "asset", Channel: "assets", Sequence: 2},
+	}}}
+	assert.Equal(T, 2, p.getChaincodeDeployment("asset", "assets").Sequence)
+	assert.Nil(T, p.getChaincodeDeployment("other", "firefly"))
+}
@@ -17,6 +17,7 @@
 package fabric
 
 import (
+	"fmt"
 	"io/ioutil"
 
 	"gopkg.in/yaml.v2"
@@ -110,7 +111,30 @@ type FabricNetworkConfig struct {
 	Version                string                    `yaml:"version,omitempty"`
 }
 
-func WriteNetworkConfig(outputPath string) error {
+func WriteNetworkConfig(outputPath string, channels []string, orderers []string) error {
+	channelConfigs := make(map[string]*Channel, len(channels))
+	for _, channel := range channels {
+		channelConfigs[channel] = &Channel{
+			Orderers: orderers,
+			Peers: map[string]*ChannelPeer{
+				"fabric_peer": {
+					ChaincodeQuery: true,
+					EndorsingPeer:  true,
+					EventSource:    true,
+					LedgerQuery:    true,
+				},
+			},
+		}
+	}
+	ordererConfigs := make(map[string]*NetworkEntity, len(orderers))
+	for _, orderer := range orderers {
+		ordererConfigs[orderer] = &NetworkEntity{
+			TLSCACerts: &Path{
+				Path: "/etc/firefly/organizations/ordererOrganizations/example.com/tlsca/tlsca.example.com-cert.pem",
+			},
+			URL: fmt.Sprintf("grpcs://%s:7050", orderer),
+		}
+	}
 	networkConfig := &FabricNetworkConfig{
 		CertificateAuthorities: map[string]*NetworkEntity{
 			"org1.example.com": {
@@ -124,19 +148,7 @@ func WriteNetworkConfig(outputPath string) error {
 				},
 			},
 		},
-		Channels: map[string]*Channel{
-			"firefly": {
-				Orderers: []string{"fabric_orderer"},
-				Peers: map[string]*ChannelPeer{
-					"fabric_peer": {
-						ChaincodeQuery: true,
-						EndorsingPeer:  true,
-						EventSource:    true,
-						LedgerQuery:    true,
-					},
-				},
-			},
-		},
+		Channels: channelConfigs,
 		Client: &Client{
 			BCCSP: &BCCSP{
 				Security: &BCCSPSecurity{
@@ -173,14 +185,7 @@ func WriteNetworkConfig(outputPath string) error {
 				},
 			},
 		},
-		Orderers: map[string]*NetworkEntity{
-			"fabric_orderer": {
-				TLSCACerts: &Path{
-					Path: "/etc/firefly/organizations/ordererOrganizations/example.com/tlsca/tlsca.example.com-cert.pem",
-				},
-				URL: "grpcs://fabric_orderer:7050",
-			},
-		},
+		Orderers: ordererConfigs,
 		Organizations: map[string]*Organization{
 			"org1.example.com": {
 				CertificateAuthorities: []string{"org1.example.com"},
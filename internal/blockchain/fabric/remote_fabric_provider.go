@@ -0,0 +1,89 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric/fabconnect"
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// RemoteFabricProvider connects the stack's fabconnect instances to an existing Fabric network using an
+// imported connection profile. The FireFly chaincode must already be committed on the network's channel,
+// as the stack does not have admin access to the network's peers
+type RemoteFabricProvider struct {
+	Verbose bool
+	Log     log.Logger
+	Stack   *types.Stack
+}
+
+func (p *RemoteFabricProvider) WriteConfig() error {
+	blockchainDirectory := path.Join(constants.StacksDir, p.Stack.Name, "blockchain")
+	ccp, err := ioutil.ReadFile(p.Stack.Fabric.ConnectionProfile)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(blockchainDirectory, "ccp.yaml"), ccp, 0755); err != nil {
+		return err
+	}
+	return fabconnect.WriteFabconnectConfig(path.Join(blockchainDirectory, "fabconnect.yaml"))
+}
+
+func (p *RemoteFabricProvider) FirstTimeSetup() error {
+	return nil
+}
+
+func (p *RemoteFabricProvider) DeploySmartContracts() error {
+	p.Log.Info(fmt.Sprintf("using chaincode '%s' on channel '%s'", p.Stack.Fabric.GetChaincodeName(), p.Stack.Fabric.GetChannel()))
+	return registerIdentities(p.Stack, p.Log)
+}
+
+func (p *RemoteFabricProvider) UpgradeSmartContracts() error {
+	return errors.New("chaincode on a remote fabric network must be upgraded by the network's administrators")
+}
+
+func (p *RemoteFabricProvider) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	return "", errors.New("chaincode on a remote fabric network must be deployed by the network's administrators")
+}
+
+func (p *RemoteFabricProvider) PreStart() error {
+	return nil
+}
+
+func (p *RemoteFabricProvider) PostStart() error {
+	return nil
+}
+
+func (p *RemoteFabricProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	return getFabconnectServiceDefinitions(p.Stack, nil)
+}
+
+func (p *RemoteFabricProvider) GetFireflyConfig(m *types.Member) (blockchainConfig *core.BlockchainConfig, orgConfig *core.OrgConfig) {
+	return getFireflyConfig(p.Stack, m)
+}
+
+func (p *RemoteFabricProvider) Reset() error {
+	return nil
+}
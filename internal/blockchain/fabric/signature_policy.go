@@ -0,0 +1,137 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Principals are written as '<msp id>.<role>'
+var principalRegex = regexp.MustCompile(`^[[:alnum:].-]+\.(member|admin|client|peer|orderer)$`)
+
+// ValidateSignaturePolicy checks that a policy is a valid Fabric signature policy, such as
+// "OR('Org1MSP.member', AND('Org2MSP.peer', 'Org3MSP.peer'))", so that a mistake is found when
+// the stack is created rather than when chaincode is first approved
+func ValidateSignaturePolicy(policy string) error {
+	p := &policyParser{input: policy}
+	err := p.parseExpression()
+	if err == nil {
+		p.skipSpaces()
+		if p.pos < len(p.input) {
+			err = fmt.Errorf("unexpected '%s'", p.input[p.pos:])
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid signature policy: %s", policy, err)
+	}
+	return nil
+}
+
+type policyParser struct {
+	input string
+	pos   int
+}
+
+func (p *policyParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *policyParser) next(chars string) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && strings.IndexByte(chars, p.input[p.pos]) >= 0 {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseExpression parses a quoted principal, or an AND, OR or OutOf gate and its arguments
+func (p *policyParser) parseExpression() error {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return errors.New("unexpected end of policy")
+	}
+	if quote := p.input[p.pos]; quote == '\'' || quote == '"' {
+		end := strings.IndexByte(p.input[p.pos+1:], quote)
+		if end < 0 {
+			return errors.New("unterminated principal")
+		}
+		principal := p.input[p.pos+1 : p.pos+1+end]
+		if !principalRegex.MatchString(principal) {
+			return fmt.Errorf("'%s' is not a valid principal - principals must be in the format <msp id>.<member|admin|client|peer|orderer>", principal)
+		}
+		p.pos += end + 2
+		return nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] >= 'a' && p.input[p.pos] <= 'z' || p.input[p.pos] >= 'A' && p.input[p.pos] <= 'Z') {
+		p.pos++
+	}
+	gate := p.input[start:p.pos]
+	isOutOf := false
+	switch gate {
+	case "AND", "And", "and", "OR", "Or", "or":
+	case "OutOf", "outof", "OUTOF":
+		isOutOf = true
+	default:
+		return fmt.Errorf("expected a quoted principal or AND, OR or OutOf at '%s'", p.input[start:])
+	}
+	if !p.next("(") {
+		return fmt.Errorf("expected '(' after %s", gate)
+	}
+
+	n := 0
+	if isOutOf {
+		p.skipSpaces()
+		numStart := p.pos
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		var err error
+		if n, err = strconv.Atoi(p.input[numStart:p.pos]); err != nil {
+			return errors.New("OutOf must start with the number of policies to satisfy")
+		}
+		if !p.next(",") {
+			return errors.New("expected ',' after the number of policies to satisfy")
+		}
+	}
+
+	count := 0
+	for {
+		if err := p.parseExpression(); err != nil {
+			return err
+		}
+		count++
+		if p.next(")") {
+			break
+		}
+		if !p.next(",") {
+			return fmt.Errorf("expected ',' or ')' in %s", gate)
+		}
+	}
+	if isOutOf && (n < 1 || n > count) {
+		return fmt.Errorf("OutOf(%d, ...) must be satisfied by between 1 and %d policies", n, count)
+	}
+	return nil
+}
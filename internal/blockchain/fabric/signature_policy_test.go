@@ -0,0 +1,57 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSignaturePolicy(T *testing.T) {
+	testCases := []struct {
+		policy string
+		err    string
+	}{
+		{policy: "OR('Org1MSP.member')"},
+		{policy: `AND("Org1MSP.peer", "Org2MSP.peer")`},
+		{policy: "OR('Org1MSP.member', AND('Org2MSP.peer', 'Org3MSP.admin'))"},
+		{policy: "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')"},
+		{policy: "outof(1,'org-1.example.com.client')"},
+		{policy: "'Org1MSP.member'"},
+		{policy: "OR('Org1MSP.memer')", err: "'Org1MSP.memer' is not a valid principal"},
+		{policy: "OR(Org1MSP.member)", err: "expected a quoted principal or AND, OR or OutOf at 'Org1MSP.member)'"},
+		{policy: "XOR('Org1MSP.member')", err: "expected a quoted principal or AND, OR or OutOf at 'XOR('Org1MSP.member')'"},
+		{policy: "OR('Org1MSP.member'", err: "expected ',' or ')' in OR"},
+		{policy: "OR('Org1MSP.member)", err: "unterminated principal"},
+		{policy: "OR()", err: "expected a quoted principal or AND, OR or OutOf at ')'"},
+		{policy: "OR('Org1MSP.member'))", err: "unexpected ')'"},
+		{policy: "OutOf('Org1MSP.member')", err: "OutOf must start with the number of policies to satisfy"},
+		{policy: "OutOf(3, 'Org1MSP.peer', 'Org2MSP.peer')", err: "OutOf(3, ...) must be satisfied by between 1 and 2 policies"},
+		{policy: "", err: "unexpected end of policy"},
+	}
+	for _, tc := range testCases {
+		T.Run(tc.policy, func(t *testing.T) {
+			err := ValidateSignaturePolicy(tc.policy)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
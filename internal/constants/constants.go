@@ -23,6 +23,12 @@ import (
 
 var homeDir, _ = os.UserHomeDir()
 var StacksDir = filepath.Join(homeDir, ".firefly", "stacks")
+var ManifestCacheDir = filepath.Join(homeDir, ".firefly", "manifests")
 
 var IPFSImageName = "ipfs/go-ipfs"
 var PostgresImageName = "postgres"
+var MinioImageName = "minio/minio"
+var MinioClientImageName = "minio/mc"
+var SolcImageName = "ethereum/solc:0.8.11"
+var SQLiteImageName = "keinos/sqlite3"
+var AlpineImageName = "alpine"
@@ -0,0 +1,107 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReadFireflyConfigOverrides reads a YAML file of FireFly config to merge into the config that is generated for each member.
+// Any keys are allowed, including ones the CLI doesn't know about
+func ReadFireflyConfigOverrides(filePath string) (map[string]interface{}, error) {
+	bytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var overrides interface{}
+	if err := yaml.Unmarshal(bytes, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid FireFly config overrides in %s: %s", filePath, err)
+	}
+	if overrides == nil {
+		return map[string]interface{}{}, nil
+	}
+	overridesMap, ok := normalizeYAML(overrides).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid FireFly config overrides in %s: the file must contain a YAML mapping", filePath)
+	}
+	return overridesMap, nil
+}
+
+// MergeFireflyConfig deep merges each set of overrides into the config in turn. Mappings are merged key by key,
+// any other value replaces the generated one, and a null value removes the key from the config
+func MergeFireflyConfig(config *FireflyConfig, overrides ...map[string]interface{}) (map[string]interface{}, error) {
+	bytes, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var generated interface{}
+	if err := yaml.Unmarshal(bytes, &generated); err != nil {
+		return nil, err
+	}
+	merged, ok := normalizeYAML(generated).(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+	}
+	for _, o := range overrides {
+		mergeYAML(merged, o)
+	}
+	return merged, nil
+}
+
+func mergeYAML(dest map[string]interface{}, src map[string]interface{}) {
+	for key, value := range src {
+		if value == nil {
+			delete(dest, key)
+			continue
+		}
+		srcMap, srcIsMap := value.(map[string]interface{})
+		destMap, destIsMap := dest[key].(map[string]interface{})
+		if srcIsMap && destIsMap {
+			mergeYAML(destMap, srcMap)
+		} else {
+			dest[key] = value
+		}
+	}
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values that the YAML parser produces into
+// map[string]interface{}, so that overrides can be saved in the stack's JSON config
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return m
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeFireflyConfig(T *testing.T) {
+	config := &FireflyConfig{
+		Log:   &LogConfig{Level: "debug"},
+		Debug: &HttpServerConfig{Port: 6060},
+		HTTP:  &HttpServerConfig{Port: 5000, Address: "0.0.0.0"},
+	}
+	dir := T.TempDir()
+	stackOverrides := filepath.Join(dir, "stack.yml")
+	memberOverrides := filepath.Join(dir, "member.yml")
+	assert.NoError(T, ioutil.WriteFile(stackOverrides, []byte("log:\n  level: info\ndebug: null\nevent:\n  dispatcher:\n    batchTimeout: 1s\n"), 0755))
+	assert.NoError(T, ioutil.WriteFile(memberOverrides, []byte("http:\n  port: 5005\nevent:\n  dispatcher:\n    bufferLength: 10\n"), 0755))
+
+	stack, err := ReadFireflyConfigOverrides(stackOverrides)
+	assert.NoError(T, err)
+	member, err := ReadFireflyConfigOverrides(memberOverrides)
+	assert.NoError(T, err)
+	merged, err := MergeFireflyConfig(config, stack, member)
+	assert.NoError(T, err)
+
+	assert.Equal(T, map[string]interface{}{"level": "info"}, merged["log"])
+	assert.NotContains(T, merged, "debug")
+	assert.Equal(T, map[string]interface{}{"port": 5005, "address": "0.0.0.0"}, merged["http"])
+	assert.Equal(T, map[string]interface{}{"dispatcher": map[string]interface{}{"batchTimeout": "1s", "bufferLength": 10}}, merged["event"])
+}
+
+func TestReadFireflyConfigOverridesNotMapping(T *testing.T) {
+	overrides := filepath.Join(T.TempDir(), "overrides.yml")
+	assert.NoError(T, ioutil.WriteFile(overrides, []byte("- a\n- b\n"), 0755))
+	_, err := ReadFireflyConfigOverrides(overrides)
+	assert.Regexp(T, "must contain a YAML mapping", err)
+}
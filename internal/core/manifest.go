@@ -20,21 +20,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/log"
 	"github.com/hyperledger/firefly-cli/pkg/types"
 )
 
-func GetLatestReleaseManifest() (*types.VersionManifest, error) {
+// GetLatestReleaseManifest fetches the manifest of the latest FireFly release, or uses the one that was fetched last
+// if GitHub can't be reached
+func GetLatestReleaseManifest(l log.Logger) (*types.VersionManifest, error) {
 	latestRelease, err := getLatestFireFlyRelease()
 	if err != nil {
+		if manifest, cacheErr := readCachedManifest("latest"); cacheErr == nil {
+			return manifest, nil
+		}
 		return nil, err
 	}
-	return GetReleaseManifest(latestRelease.TagName)
+	manifest, err := GetReleaseManifest(l, latestRelease.TagName)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCachedManifest("latest", manifest); err != nil {
+		l.Warn(fmt.Sprintf("unable to cache the manifest for the latest release: %s", err))
+	}
+	return manifest, nil
 }
 
-func GetReleaseManifest(version string) (*types.VersionManifest, error) {
+// GetReleaseManifest fetches the manifest of a FireFly release and caches it, so that stacks can still be created
+// with the cached manifest if GitHub can't be reached. Failing to write the cache is not fatal.
+func GetReleaseManifest(l log.Logger, version string) (*types.VersionManifest, error) {
 	manifest := &types.VersionManifest{}
 	if err := request("GET", fmt.Sprintf("https://raw.githubusercontent.com/hyperledger/firefly/%s/manifest.json", version), nil, &manifest); err != nil {
+		if cached, cacheErr := readCachedManifest(version); cacheErr == nil {
+			return cached, nil
+		}
 		return nil, err
 	}
 	if manifest.FireFly == nil {
@@ -44,9 +66,40 @@ func GetReleaseManifest(version string) (*types.VersionManifest, error) {
 			Tag:   version,
 		}
 	}
+	if err := writeCachedManifest(version, manifest); err != nil {
+		l.Warn(fmt.Sprintf("unable to cache the manifest for %s: %s", version, err))
+	}
+	return manifest, nil
+}
+
+func getCachedManifestPath(version string) string {
+	// Branch names used as versions can contain slashes
+	return filepath.Join(constants.ManifestCacheDir, strings.ReplaceAll(version, "/", "_")+".json")
+}
+
+func readCachedManifest(version string) (*types.VersionManifest, error) {
+	d, err := ioutil.ReadFile(getCachedManifestPath(version))
+	if err != nil {
+		return nil, err
+	}
+	var manifest *types.VersionManifest
+	if err := json.Unmarshal(d, &manifest); err != nil {
+		return nil, err
+	}
 	return manifest, nil
 }
 
+func writeCachedManifest(version string, manifest *types.VersionManifest) error {
+	if err := os.MkdirAll(constants.ManifestCacheDir, 0755); err != nil {
+		return err
+	}
+	d, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(getCachedManifestPath(version), d, 0644)
+}
+
 func getLatestFireFlyRelease() (*types.GitHubRelease, error) {
 	release := &types.GitHubRelease{}
 	if err := request("GET", "https://api.github.com/repos/hyperledger/firefly/releases/latest", nil, release); err != nil {
@@ -72,3 +125,47 @@ func ReadManifestFile(p string) (*types.VersionManifest, error) {
 	}
 	return manifest, err
 }
+
+// ManifestChange is a component whose docker image differs between two manifests. The image is empty if the
+// component isn't in one of the manifests
+type ManifestChange struct {
+	Component    string
+	CurrentImage string
+	NewImage     string
+}
+
+// DiffManifests returns the components whose images differ between the current and new manifests
+func DiffManifests(current, new *types.VersionManifest) []*ManifestChange {
+	currentEntries := getManifestEntries(current)
+	newEntries := getManifestEntries(new)
+	changes := []*ManifestChange{}
+	for i, component := range manifestComponents {
+		currentImage := getManifestImage(currentEntries[i])
+		newImage := getManifestImage(newEntries[i])
+		if currentImage != newImage {
+			changes = append(changes, &ManifestChange{
+				Component:    component,
+				CurrentImage: currentImage,
+				NewImage:     newImage,
+			})
+		}
+	}
+	return changes
+}
+
+// The names of the components in the manifest file, in the same order as VersionManifest.Entries()
+var manifestComponents = []string{"firefly", "ethconnect", "fabconnect", "dataexchange-https", "tokens-erc1155"}
+
+func getManifestEntries(manifest *types.VersionManifest) []*types.ManifestEntry {
+	if manifest == nil {
+		return make([]*types.ManifestEntry, len(manifestComponents))
+	}
+	return manifest.Entries()
+}
+
+func getManifestImage(entry *types.ManifestEntry) string {
+	if entry == nil {
+		return ""
+	}
+	return entry.GetDockerImageString()
+}
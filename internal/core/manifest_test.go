@@ -19,6 +19,9 @@ package core
 import (
 	"testing"
 
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,7 +33,7 @@ func TestGetLatestFireFlyRelease(T *testing.T) {
 }
 
 func TestGetFireFlyManifest(T *testing.T) {
-	manifest, err := GetReleaseManifest("main")
+	manifest, err := GetReleaseManifest(&log.StdoutLogger{}, "main")
 	assert.NoError(T, err)
 	assert.NotNil(T, manifest)
 	assert.NotNil(T, manifest.FireFly)
@@ -41,7 +44,7 @@ func TestGetFireFlyManifest(T *testing.T) {
 }
 
 func TestGetLatestReleaseManifest(T *testing.T) {
-	manifest, err := GetLatestReleaseManifest()
+	manifest, err := GetLatestReleaseManifest(&log.StdoutLogger{})
 	assert.NoError(T, err)
 	assert.NotNil(T, manifest)
 	assert.NotNil(T, manifest.FireFly)
@@ -50,3 +53,42 @@ func TestGetLatestReleaseManifest(T *testing.T) {
 	assert.NotNil(T, manifest.DataExchange)
 	assert.NotNil(T, manifest.Tokens)
 }
+
+func TestDiffManifests(T *testing.T) {
+	current := &types.VersionManifest{
+		FireFly:      &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly", Tag: "v0.11.0", SHA: "aaaa"},
+		Ethconnect:   &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-ethconnect", Tag: "v3.0.4", SHA: "bbbb"},
+		DataExchange: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-dataexchange-https", Tag: "v0.9.0"},
+		Tokens:       &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-tokens-erc1155", Tag: "v0.9.0"},
+	}
+	new := &types.VersionManifest{
+		FireFly:      &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly", Tag: "v0.12.0", SHA: "cccc"},
+		Ethconnect:   &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-ethconnect", Tag: "v3.0.4", SHA: "bbbb"},
+		Fabconnect:   &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-fabconnect", Tag: "v0.9.0"},
+		DataExchange: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-dataexchange-https", Tag: "v0.9.0"},
+	}
+	changes := DiffManifests(current, new)
+	assert.Equal(T, []*ManifestChange{
+		{Component: "firefly", CurrentImage: "ghcr.io/hyperledger/firefly@sha256:aaaa", NewImage: "ghcr.io/hyperledger/firefly@sha256:cccc"},
+		{Component: "fabconnect", CurrentImage: "", NewImage: "ghcr.io/hyperledger/firefly-fabconnect:v0.9.0"},
+		{Component: "tokens-erc1155", CurrentImage: "ghcr.io/hyperledger/firefly-tokens-erc1155:v0.9.0", NewImage: ""},
+	}, changes)
+	assert.Empty(T, DiffManifests(current, current))
+}
+
+func TestManifestCache(T *testing.T) {
+	defer func(dir string) { constants.ManifestCacheDir = dir }(constants.ManifestCacheDir)
+	constants.ManifestCacheDir = T.TempDir()
+
+	_, err := readCachedManifest("feature/cache")
+	assert.Error(T, err)
+
+	manifest := &types.VersionManifest{
+		FireFly:    &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly", Tag: "v0.12.0"},
+		Ethconnect: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-ethconnect", Tag: "v3.0.4", SHA: "bbbb"},
+	}
+	assert.NoError(T, writeCachedManifest("feature/cache", manifest))
+	cached, err := readCachedManifest("feature/cache")
+	assert.NoError(T, err)
+	assert.Equal(T, manifest, cached)
+}
@@ -0,0 +1,262 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package https
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+type DataExchangeListenerConfig struct {
+	Hostname string `json:"hostname,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Port     int    `json:"port,omitempty"`
+}
+
+type PeerConfig struct {
+	ID       string `json:"id,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+type dataExchangePeer struct {
+	ID       string
+	Endpoint string
+	Cert     []byte
+	Member   *types.Member
+}
+
+type DataExchangePeerConfig struct {
+	API   *DataExchangeListenerConfig `json:"api,omitempty"`
+	P2P   *DataExchangeListenerConfig `json:"p2p,omitempty"`
+	Peers []*PeerConfig               `json:"peers"`
+}
+
+// HTTPSProvider runs a FireFly HTTPS data exchange for each member, which sends messages and
+// blobs directly to the other members' data exchanges over mutual TLS
+type HTTPSProvider struct {
+	Log     log.Logger
+	Verbose bool
+	Stack   *types.Stack
+}
+
+// FirstTimeSetup generates each member's TLS certificate and config, and copies them into the
+// member's data exchange volume
+func (p *HTTPSProvider) FirstTimeSetup() error {
+	for _, member := range p.Stack.Members {
+		memberDXDir := GetDataDir(p.Stack, member)
+		if err := os.MkdirAll(filepath.Join(memberDXDir, "peer-certs"), 0755); err != nil {
+			return err
+		}
+
+		// TODO: remove dependency on openssl here
+		opensslCmd := exec.Command("openssl", "req", "-new", "-x509", "-nodes", "-days", "365", "-subj", getCertSubject(p.Stack, member), "-keyout", "key.pem", "-out", "cert.pem")
+		opensslCmd.Dir = memberDXDir
+		if err := opensslCmd.Run(); err != nil {
+			return err
+		}
+	}
+	return p.WritePeers()
+}
+
+// WritePeers writes each member's config, with the other members of the network as its peers if the stack
+// prepopulates them, and copies the config, certificates and peer certificates into the member's volume.
+// Every member's certificate must already exist
+func (p *HTTPSProvider) WritePeers() error {
+	peers := []*dataExchangePeer{}
+	if p.Stack.PrepopulateDataExchangePeers {
+		var err error
+		if peers, err = p.getPeers(); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range p.Stack.Members {
+		memberDXDir := GetDataDir(p.Stack, member)
+		volumeName := fmt.Sprintf("%s_dataexchange_%s", p.Stack.Name, member.ID)
+		docker.MkdirInVolume(volumeName, "peer-certs", p.Verbose)
+
+		peerConfigs := []*PeerConfig{}
+		for _, peer := range peers {
+			if peer.Member == member {
+				continue
+			}
+			peerCertPath := filepath.Join(memberDXDir, "peer-certs", peer.ID+".pem")
+			if err := ioutil.WriteFile(peerCertPath, peer.Cert, 0755); err != nil {
+				return err
+			}
+			docker.CopyFileToVolume(volumeName, peerCertPath, fmt.Sprintf("/peer-certs/%s.pem", peer.ID), p.Verbose)
+			peerConfigs = append(peerConfigs, &PeerConfig{ID: peer.ID, Endpoint: peer.Endpoint})
+		}
+
+		dataExchangeConfig := p.GenerateConfig(member, peerConfigs)
+		configBytes, err := json.Marshal(dataExchangeConfig)
+		if err != nil {
+			return err
+		}
+		ioutil.WriteFile(filepath.Join(memberDXDir, "config.json"), configBytes, 0755)
+
+		// Copy files into docker volumes
+		docker.CopyFileToVolume(volumeName, filepath.Join(memberDXDir, "config.json"), "/config.json", p.Verbose)
+		docker.CopyFileToVolume(volumeName, filepath.Join(memberDXDir, "cert.pem"), "/cert.pem", p.Verbose)
+		docker.CopyFileToVolume(volumeName, filepath.Join(memberDXDir, "key.pem"), "/key.pem", p.Verbose)
+	}
+	return nil
+}
+
+func (p *HTTPSProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	serviceDefinitions := make([]*docker.ServiceDefinition, 0, len(p.Stack.Members))
+	for _, member := range p.Stack.Members {
+		service := &docker.Service{
+			Image:         p.Stack.VersionManifest.DataExchange.GetDockerImageString(),
+			ContainerName: fmt.Sprintf("%s_dataexchange_%s", p.Stack.Name, member.ID),
+			Ports:         []string{fmt.Sprintf("%d:3000", member.ExposedDataexchangePort)},
+			Volumes:       []string{fmt.Sprintf("dataexchange_%s:/data", member.ID)},
+			Logging:       docker.StandardLogOptions,
+		}
+		// Members on other machines send messages to the p2p port of each member's data exchange
+		if p.Stack.Host != "" {
+			service.Ports = append(service.Ports, fmt.Sprintf("%d:3001", member.ExposedDataexchangeP2PPort))
+		}
+		serviceDefinitions = append(serviceDefinitions, &docker.ServiceDefinition{
+			ServiceName: "dataexchange_" + member.ID,
+			Service:     service,
+			VolumeNames: []string{"dataexchange_" + member.ID},
+		})
+	}
+	return serviceDefinitions
+}
+
+func (p *HTTPSProvider) GetFireflyConfig(m *types.Member) *core.DataExchangeConfig {
+	url := fmt.Sprintf("http://dataexchange_%s:3000", m.ID)
+	if m.External {
+		url = fmt.Sprintf("http://127.0.0.1:%v", m.ExposedDataexchangePort)
+	}
+	return &core.DataExchangeConfig{
+		Type: "https",
+		HTTPS: &core.HttpEndpointConfig{
+			URL: url,
+		},
+	}
+}
+
+// GenerateConfig returns the data exchange config for a member. Any peers given are known to the data exchange
+// as soon as it starts, instead of being added when FireFly core registers the other members' nodes
+func (p *HTTPSProvider) GenerateConfig(member *types.Member, peers []*PeerConfig) *DataExchangePeerConfig {
+	return &DataExchangePeerConfig{
+		API: &DataExchangeListenerConfig{
+			Hostname: "0.0.0.0",
+			Port:     3000,
+		},
+		P2P: &DataExchangeListenerConfig{
+			Hostname: "0.0.0.0",
+			Port:     3001,
+			Endpoint: GetP2PEndpoint(p.Stack, member),
+		},
+		Peers: peers,
+	}
+}
+
+// getPeers returns the endpoint and certificate of the data exchange of every member in the network,
+// including members on other machines that this stack joined
+func (p *HTTPSProvider) getPeers() ([]*dataExchangePeer, error) {
+	peers := []*dataExchangePeer{}
+	ids := map[string]bool{}
+	for _, member := range p.Stack.Members {
+		cert, err := ioutil.ReadFile(filepath.Join(GetDataDir(p.Stack, member), "cert.pem"))
+		if err != nil {
+			return nil, err
+		}
+		id, err := getPeerID(cert)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+		peers = append(peers, &dataExchangePeer{ID: id, Endpoint: GetP2PEndpoint(p.Stack, member), Cert: cert, Member: member})
+	}
+	for _, member := range p.Stack.RemoteMembers {
+		if member.DataExchangeCert == "" {
+			continue
+		}
+		id, err := getPeerID([]byte(member.DataExchangeCert))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data exchange certificate for remote org '%s': %s", member.OrgName, err)
+		}
+		if ids[id] {
+			p.Log.Warn(fmt.Sprintf("not adding the data exchange of remote org '%s' as a peer - its ID '%s' is already used by another peer", member.OrgName, id))
+			continue
+		}
+		ids[id] = true
+		peers = append(peers, &dataExchangePeer{ID: id, Endpoint: member.DataExchangeEndpoint, Cert: []byte(member.DataExchangeCert)})
+	}
+	return peers, nil
+}
+
+// getCertSubject returns the subject of a member's certificate, which the data exchange takes its peer ID from.
+// Member IDs are only unique within a stack, so stacks that members on other machines can join use the org
+// name, which is unique across the whole network
+func getCertSubject(s *types.Stack, member *types.Member) string {
+	if s.Host != "" {
+		return fmt.Sprintf("/CN=%s/O=%s", member.OrgName, member.OrgName)
+	}
+	return fmt.Sprintf("/CN=dataexchange_%s/O=member_%s", member.ID, member.ID)
+}
+
+// getPeerID returns the ID a data exchange identifies itself with, which is the organization in its
+// certificate, or the common name if it has no organization
+func getPeerID(certPEM []byte) (string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "", fmt.Errorf("no PEM encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	if len(cert.Subject.Organization) > 0 && cert.Subject.Organization[0] != "" {
+		return cert.Subject.Organization[0], nil
+	}
+	if cert.Subject.CommonName == "" {
+		return "", fmt.Errorf("certificate has no organization or common name")
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// GetP2PEndpoint returns the endpoint other members send messages to. Members on other
+// machines reach it through the port exposed on the stack's host
+func GetP2PEndpoint(s *types.Stack, member *types.Member) string {
+	if s.Host != "" {
+		return fmt.Sprintf("https://%s:%d", s.Host, member.ExposedDataexchangeP2PPort)
+	}
+	return fmt.Sprintf("https://dataexchange_%s:3001", member.ID)
+}
+
+// GetDataDir returns the directory a member's data exchange certificate and config are written to
+func GetDataDir(s *types.Stack, member *types.Member) string {
+	return filepath.Join(constants.StacksDir, s.Name, "data", "dataexchange_"+member.ID)
+}
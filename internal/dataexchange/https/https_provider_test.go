@@ -0,0 +1,76 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package https
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateCert(T *testing.T, subject pkix.Name) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(T, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(T, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestGetPeerID(T *testing.T) {
+	id, err := getPeerID(generateCert(T, pkix.Name{CommonName: "org_0", Organization: []string{"org_0"}}))
+	assert.NoError(T, err)
+	assert.Equal(T, "org_0", id)
+
+	id, err = getPeerID(generateCert(T, pkix.Name{CommonName: "dataexchange_0"}))
+	assert.NoError(T, err)
+	assert.Equal(T, "dataexchange_0", id)
+
+	_, err = getPeerID([]byte("not a cert"))
+	assert.Error(T, err)
+}
+
+func TestGetCertSubject(T *testing.T) {
+	member := &types.Member{ID: "0", OrgName: "org_3"}
+	assert.Equal(T, "/CN=dataexchange_0/O=member_0", getCertSubject(&types.Stack{Name: "test"}, member))
+	assert.Equal(T, "/CN=org_3/O=org_3", getCertSubject(&types.Stack{Name: "test", Host: "192.168.1.10"}, member))
+}
+
+func TestGenerateConfigWithPeers(T *testing.T) {
+	p := &HTTPSProvider{
+		Stack: &types.Stack{Name: "test", Host: "192.168.1.10"},
+	}
+	member := &types.Member{ID: "0", ExposedDataexchangeP2PPort: 5109}
+	peers := []*PeerConfig{{ID: "org_1", Endpoint: "https://192.168.1.10:5209"}}
+	config := p.GenerateConfig(member, peers)
+	assert.Equal(T, "https://192.168.1.10:5109", config.P2P.Endpoint)
+	assert.Equal(T, peers, config.Peers)
+}
@@ -0,0 +1,199 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The override file is never written by the CLI when a stack's docker-compose.yml is generated,
+// so local customizations survive the compose file being regenerated
+const ComposeOverrideFileName = "docker-compose.override.yml"
+
+// getComposeFileArgs returns the compose files to use for a stack, including its override file if there is one
+func getComposeFileArgs(workingDir string) []string {
+	if _, err := os.Stat(filepath.Join(workingDir, ComposeOverrideFileName)); err != nil {
+		return []string{}
+	}
+	return []string{"-f", "docker-compose.yml", "-f", ComposeOverrideFileName}
+}
+
+// UpdateComposeOverride sets environment variables and adds volumes for a service in a stack's override file.
+// The rest of the file is preserved, so it can also be edited by hand
+func UpdateComposeOverride(workingDir string, composeVersion string, serviceName string, env []string, volumes []string) error {
+	override, err := readComposeOverride(workingDir)
+	if err != nil {
+		return err
+	}
+	if override == nil {
+		override = yaml.MapSlice{}
+	}
+
+	// The version must match the stack's compose file, or docker-compose refuses to merge them
+	override = setMapSliceValue(override, "version", composeVersion)
+	services, err := getMapSlice(override, "services")
+	if err != nil {
+		return err
+	}
+	service, err := getMapSlice(services, serviceName)
+	if err != nil {
+		return err
+	}
+
+	if len(env) > 0 {
+		environment, err := setEnvironment(service, env)
+		if err != nil {
+			return fmt.Errorf("unable to update the environment of %s: %s", serviceName, err)
+		}
+		service = setMapSliceValue(service, "environment", environment)
+	}
+	if len(volumes) > 0 {
+		existing, _ := getMapSliceValue(service, "volumes")
+		serviceVolumes, ok := existing.([]interface{})
+		if existing != nil && !ok {
+			return fmt.Errorf("unable to update the volumes of %s: volumes must be a list", serviceName)
+		}
+		for _, volume := range volumes {
+			if !containsValue(serviceVolumes, volume) {
+				serviceVolumes = append(serviceVolumes, volume)
+			}
+		}
+		service = setMapSliceValue(service, "volumes", serviceVolumes)
+	}
+
+	services = setMapSliceValue(services, serviceName, service)
+	override = setMapSliceValue(override, "services", services)
+	return writeComposeOverride(workingDir, override)
+}
+
+// SetComposeOverrideVersion updates the version of a stack's override file, if it has one, to match the
+// stack's compose file. It is called whenever the compose file is written, as newer releases of the CLI
+// may generate the compose file with a different version
+func SetComposeOverrideVersion(workingDir string, composeVersion string) error {
+	override, err := readComposeOverride(workingDir)
+	if err != nil || override == nil {
+		return err
+	}
+	if version, ok := getMapSliceValue(override, "version"); ok && version == composeVersion {
+		return nil
+	}
+	return writeComposeOverride(workingDir, setMapSliceValue(override, "version", composeVersion))
+}
+
+// readComposeOverride returns the contents of a stack's override file, or nil if it does not have one
+func readComposeOverride(workingDir string) (yaml.MapSlice, error) {
+	overridePath := filepath.Join(workingDir, ComposeOverrideFileName)
+	bytes, err := ioutil.ReadFile(overridePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	override := yaml.MapSlice{}
+	if err := yaml.Unmarshal(bytes, &override); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", overridePath, err)
+	}
+	return override, nil
+}
+
+func writeComposeOverride(workingDir string, override yaml.MapSlice) error {
+	bytes, err := yaml.Marshal(override)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(workingDir, ComposeOverrideFileName), bytes, 0755)
+}
+
+// setEnvironment sets each KEY=VALUE in a service's environment, which can be either a mapping or a list
+func setEnvironment(service yaml.MapSlice, env []string) (interface{}, error) {
+	existing, _ := getMapSliceValue(service, "environment")
+	switch environment := existing.(type) {
+	case []interface{}:
+		for _, e := range env {
+			key := strings.SplitN(e, "=", 2)[0]
+			replaced := false
+			for i, item := range environment {
+				if s, ok := item.(string); ok && strings.SplitN(s, "=", 2)[0] == key {
+					environment[i] = e
+					replaced = true
+				}
+			}
+			if !replaced {
+				environment = append(environment, e)
+			}
+		}
+		return environment, nil
+	case yaml.MapSlice:
+		for _, e := range env {
+			parts := strings.SplitN(e, "=", 2)
+			environment = setMapSliceValue(environment, parts[0], parts[1])
+		}
+		return environment, nil
+	case nil:
+		return setEnvironment(yaml.MapSlice{{Key: "environment", Value: yaml.MapSlice{}}}, env)
+	default:
+		return nil, fmt.Errorf("environment must be a mapping or a list")
+	}
+}
+
+func getMapSliceValue(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// getMapSlice returns the mapping stored under a key, or an empty mapping if the key is not set
+func getMapSlice(m yaml.MapSlice, key string) (yaml.MapSlice, error) {
+	value, _ := getMapSliceValue(m, key)
+	if value == nil {
+		return yaml.MapSlice{}, nil
+	}
+	mapSlice, ok := value.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be a mapping", key)
+	}
+	return mapSlice, nil
+}
+
+// setMapSliceValue replaces the value of a key, keeping its position, or adds the key to the end of the mapping
+func setMapSliceValue(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range m {
+		if item.Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
+
+func containsValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestUpdateComposeOverride(T *testing.T) {
+	testCases := []struct {
+		name     string
+		existing string
+		env      []string
+		volumes  []string
+		expected string
+	}{
+		{
+			name:    "new file",
+			env:     []string{"FOO=bar"},
+			volumes: []string{"./data:/data"},
+			expected: `version: "2.1"
+services:
+  firefly_core_0:
+    environment:
+      FOO: bar
+    volumes:
+    - ./data:/data
+`,
+		},
+		{
+			name: "keeps user keys",
+			existing: `version: "2.1"
+x-custom: kept
+services:
+  ipfs_0:
+    restart: always
+  firefly_core_0:
+    image: my-firefly
+    environment:
+    - FOO=old
+    - OTHER=1
+    volumes:
+    - ./data:/data
+`,
+			env:     []string{"FOO=new", "BAR=2"},
+			volumes: []string{"./data:/data", "./logs:/logs"},
+			expected: `version: "2.1"
+x-custom: kept
+services:
+  ipfs_0:
+    restart: always
+  firefly_core_0:
+    image: my-firefly
+    environment:
+    - FOO=new
+    - OTHER=1
+    - BAR=2
+    volumes:
+    - ./data:/data
+    - ./logs:/logs
+`,
+		},
+		{
+			name: "merges into an environment mapping",
+			existing: `services:
+  firefly_core_0:
+    environment:
+      FOO: old
+      OTHER: "1"
+`,
+			env: []string{"FOO=new", "BAR=2"},
+			expected: `services:
+  firefly_core_0:
+    environment:
+      FOO: new
+      OTHER: "1"
+      BAR: "2"
+version: "2.1"
+`,
+		},
+	}
+	for _, tc := range testCases {
+		dir := T.TempDir()
+		overridePath := filepath.Join(dir, ComposeOverrideFileName)
+		if tc.existing != "" {
+			assert.NoError(T, ioutil.WriteFile(overridePath, []byte(tc.existing), 0755), tc.name)
+		}
+		assert.NoError(T, UpdateComposeOverride(dir, "2.1", "firefly_core_0", tc.env, tc.volumes), tc.name)
+		bytes, err := ioutil.ReadFile(overridePath)
+		assert.NoError(T, err, tc.name)
+		assert.Equal(T, tc.expected, string(bytes), tc.name)
+	}
+}
+
+func TestUpdateComposeOverrideInvalidFile(T *testing.T) {
+	dir := T.TempDir()
+	overridePath := filepath.Join(dir, ComposeOverrideFileName)
+
+	assert.NoError(T, ioutil.WriteFile(overridePath, []byte("services: ["), 0755))
+	assert.Regexp(T, "unable to parse", UpdateComposeOverride(dir, "2.1", "firefly_core_0", []string{"FOO=bar"}, nil))
+
+	assert.NoError(T, ioutil.WriteFile(overridePath, []byte("services:\n  firefly_core_0:\n    volumes: ./data:/data\n"), 0755))
+	assert.Regexp(T, "volumes must be a list", UpdateComposeOverride(dir, "2.1", "firefly_core_0", nil, []string{"./logs:/logs"}))
+}
+
+func TestUpdateComposeOverrideVersion(T *testing.T) {
+	dir := T.TempDir()
+	overridePath := filepath.Join(dir, ComposeOverrideFileName)
+
+	assert.NoError(T, SetComposeOverrideVersion(dir, "2.2"))
+	_, err := ioutil.ReadFile(overridePath)
+	assert.Error(T, err)
+
+	assert.NoError(T, ioutil.WriteFile(overridePath, []byte("version: \"2.1\"\nservices:\n  ipfs_0:\n    restart: always\n"), 0755))
+	assert.NoError(T, SetComposeOverrideVersion(dir, "2.2"))
+	bytes, err := ioutil.ReadFile(overridePath)
+	assert.NoError(T, err)
+	assert.Equal(T, "version: \"2.2\"\nservices:\n  ipfs_0:\n    restart: always\n", string(bytes))
+
+	assert.NoError(T, ioutil.WriteFile(overridePath, []byte("version: \"2.1\"\n"), 0755))
+	assert.NoError(T, UpdateComposeOverride(dir, "2.2", "ipfs_0", []string{"FOO=bar"}, nil))
+	bytes, err = ioutil.ReadFile(overridePath)
+	assert.NoError(T, err)
+	assert.Equal(T, "version: \"2.2\"\nservices:\n  ipfs_0:\n    environment:\n      FOO: bar\n", string(bytes))
+}
+
+func TestSetEnvironment(T *testing.T) {
+	testCases := []struct {
+		name     string
+		service  yaml.MapSlice
+		env      []string
+		expected interface{}
+	}{
+		{
+			name:     "no environment",
+			service:  yaml.MapSlice{},
+			env:      []string{"FOO=bar"},
+			expected: yaml.MapSlice{{Key: "FOO", Value: "bar"}},
+		},
+		{
+			name:     "list overwrite and add",
+			service:  yaml.MapSlice{{Key: "environment", Value: []interface{}{"FOO=old", "OTHER=1"}}},
+			env:      []string{"FOO=new", "BAR=a=b"},
+			expected: []interface{}{"FOO=new", "OTHER=1", "BAR=a=b"},
+		},
+		{
+			name:     "mapping overwrite and add",
+			service:  yaml.MapSlice{{Key: "environment", Value: yaml.MapSlice{{Key: "FOO", Value: "old"}, {Key: "OTHER", Value: 1}}}},
+			env:      []string{"FOO=new", "BAR=a=b"},
+			expected: yaml.MapSlice{{Key: "FOO", Value: "new"}, {Key: "OTHER", Value: 1}, {Key: "BAR", Value: "a=b"}},
+		},
+	}
+	for _, tc := range testCases {
+		environment, err := setEnvironment(tc.service, tc.env)
+		assert.NoError(T, err, tc.name)
+		assert.Equal(T, tc.expected, environment, tc.name)
+	}
+
+	_, err := setEnvironment(yaml.MapSlice{{Key: "environment", Value: "FOO=bar"}}, []string{"FOO=baz"})
+	assert.Regexp(T, "mapping or a list", err)
+}
+
+func TestMapSliceHelpers(T *testing.T) {
+	m := yaml.MapSlice{{Key: "a", Value: 1}, {Key: "b", Value: yaml.MapSlice{{Key: "c", Value: 2}}}}
+
+	value, ok := getMapSliceValue(m, "a")
+	assert.True(T, ok)
+	assert.Equal(T, 1, value)
+	_, ok = getMapSliceValue(m, "missing")
+	assert.False(T, ok)
+
+	b, err := getMapSlice(m, "b")
+	assert.NoError(T, err)
+	assert.Equal(T, yaml.MapSlice{{Key: "c", Value: 2}}, b)
+	missing, err := getMapSlice(m, "missing")
+	assert.NoError(T, err)
+	assert.Equal(T, yaml.MapSlice{}, missing)
+	_, err = getMapSlice(m, "a")
+	assert.Regexp(T, "'a' must be a mapping", err)
+
+	m = setMapSliceValue(m, "a", 3)
+	m = setMapSliceValue(m, "d", 4)
+	assert.Equal(T, yaml.MapSlice{{Key: "a", Value: 3}, {Key: "b", Value: b}, {Key: "d", Value: 4}}, m)
+
+	assert.True(T, containsValue([]interface{}{"x", 1}, "x"))
+	assert.False(T, containsValue([]interface{}{"x", 1}, "y"))
+}
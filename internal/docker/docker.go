@@ -20,9 +20,12 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"path"
 	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
 )
 
 func CreateVolume(volumeName string, verbose bool) error {
@@ -31,11 +34,11 @@ func CreateVolume(volumeName string, verbose bool) error {
 
 func CopyFileToVolume(volumeName string, sourcePath string, destPath string, verbose bool) error {
 	fileName := path.Base(sourcePath)
-	return RunDockerCommand(".", verbose, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/source/%s", sourcePath, fileName), "-v", fmt.Sprintf("%s:/dest", volumeName), "alpine", "cp", path.Join("/", "source", fileName), path.Join("/", "dest", destPath))
+	return RunDockerCommand(".", verbose, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/source/%s", sourcePath, fileName), "-v", fmt.Sprintf("%s:/dest", volumeName), constants.AlpineImageName, "cp", path.Join("/", "source", fileName), path.Join("/", "dest", destPath))
 }
 
 func MkdirInVolume(volumeName string, directory string, verbose bool) error {
-	return RunDockerCommand(".", verbose, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/dest", volumeName), "alpine", "mkdir", "-p", path.Join("/", "dest", directory))
+	return RunDockerCommand(".", verbose, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/dest", volumeName), constants.AlpineImageName, "mkdir", "-p", path.Join("/", "dest", directory))
 }
 
 func RemoveVolume(volumeName string, verbose bool) error {
@@ -72,6 +75,7 @@ func RunDockerCommand(workingDir string, showCommand bool, pipeStdout bool, comm
 }
 
 func RunDockerComposeCommand(workingDir string, showCommand bool, pipeStdout bool, command ...string) error {
+	command = append(getComposeFileArgs(workingDir), command...)
 	dockerCmd := exec.Command("docker-compose", command...)
 	dockerCmd.Dir = workingDir
 	_, err := runCommand(dockerCmd, showCommand, pipeStdout, command...)
@@ -84,6 +88,38 @@ func RunDockerCommandBuffered(workingDir string, showCommand bool, command ...st
 	return runCommand(dockerCmd, showCommand, false, command...)
 }
 
+// RunDockerCommandInteractive runs a docker command attached to the terminal, so that the user can interact with it
+func RunDockerCommandInteractive(workingDir string, showCommand bool, command ...string) error {
+	dockerCmd := exec.Command("docker", command...)
+	dockerCmd.Dir = workingDir
+	dockerCmd.Stdin = os.Stdin
+	dockerCmd.Stdout = os.Stdout
+	dockerCmd.Stderr = os.Stderr
+	if showCommand {
+		fmt.Println(dockerCmd.String())
+	}
+	return dockerCmd.Run()
+}
+
+// RunDockerCommandStdout returns only the stdout of a docker command, for commands whose output is parsed.
+// Anything written to stderr is included in the error if the command fails
+func RunDockerCommandStdout(workingDir string, showCommand bool, command ...string) (string, error) {
+	dockerCmd := exec.Command("docker", command...)
+	dockerCmd.Dir = workingDir
+	stderr := strings.Builder{}
+	dockerCmd.Stderr = &stderr
+	if showCommand {
+		fmt.Println(dockerCmd.String())
+	}
+	output, err := dockerCmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("%s\nFailed [%d] %s", strings.Join(dockerCmd.Args, " "), exitErr.ExitCode(), stderr.String())
+	} else if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
 func runCommand(cmd *exec.Cmd, showCommand bool, pipeStdout bool, command ...string) (string, error) {
 	if showCommand {
 		fmt.Println(cmd.String())
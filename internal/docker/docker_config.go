@@ -18,6 +18,7 @@ package docker
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/hyperledger/firefly-cli/internal/constants"
 	"github.com/hyperledger/firefly-cli/pkg/types"
@@ -55,12 +56,28 @@ type Service struct {
 	HealthCheck   *HealthCheck                 `yaml:"healthcheck,omitempty"`
 	Logging       *LoggingConfig               `yaml:"logging,omitempty"`
 	WorkingDir    string                       `yaml:"working_dir,omitempty"`
+	MemLimit      string                       `yaml:"mem_limit,omitempty"`
+	CPUs          string                       `yaml:"cpus,omitempty"`
+	Restart       string                       `yaml:"restart,omitempty"`
+	Networks      []string                     `yaml:"networks,omitempty"`
+}
+
+type NetworkConfig struct {
+	External bool `yaml:"external,omitempty"`
+}
+
+// ResourceLimits are the memory and CPU limits and restart policy of a container
+type ResourceLimits struct {
+	MemLimit string
+	CPUs     string
+	Restart  string
 }
 
 type DockerComposeConfig struct {
-	Version  string              `yaml:"version,omitempty"`
-	Services map[string]*Service `yaml:"services,omitempty"`
-	Volumes  map[string]struct{} `yaml:"volumes,omitempty"`
+	Version  string                    `yaml:"version,omitempty"`
+	Services map[string]*Service       `yaml:"services,omitempty"`
+	Volumes  map[string]struct{}       `yaml:"volumes,omitempty"`
+	Networks map[string]*NetworkConfig `yaml:"networks,omitempty"`
 }
 
 var StandardLogOptions = &LoggingConfig{
@@ -73,7 +90,7 @@ var StandardLogOptions = &LoggingConfig{
 
 func CreateDockerCompose(s *types.Stack) *DockerComposeConfig {
 	compose := &DockerComposeConfig{
-		Version:  "2.1",
+		Version:  "2.2",
 		Services: make(map[string]*Service),
 		Volumes:  make(map[string]struct{}),
 	}
@@ -90,23 +107,21 @@ func CreateDockerCompose(s *types.Stack) *DockerComposeConfig {
 					fmt.Sprintf("%d:%d", member.ExposedFireflyPort, member.ExposedFireflyPort),
 					fmt.Sprintf("%d:%d", member.ExposedFireflyAdminPort, member.ExposedFireflyAdminPort),
 				},
-				Volumes: []string{fmt.Sprintf("firefly_core_%s:/etc/firefly", member.ID)},
-				DependsOn: map[string]map[string]string{
-					"dataexchange_" + member.ID: {"condition": "service_started"},
-				},
-				Logging: StandardLogOptions,
+				Volumes:   []string{fmt.Sprintf("firefly_core_%s:/etc/firefly", member.ID)},
+				DependsOn: map[string]map[string]string{},
+				Logging:   StandardLogOptions,
 			}
 
 			compose.Volumes[fmt.Sprintf("firefly_core_%s", member.ID)] = struct{}{}
 		}
 
-		if s.Database == "postgres" {
+		if s.Database == "postgres" && !s.SharedDatabase {
 			compose.Services["postgres_"+member.ID] = &Service{
 				Image:         constants.PostgresImageName,
 				ContainerName: fmt.Sprintf("%s_postgres_%s", s.Name, member.ID),
 				Ports:         []string{fmt.Sprintf("%d:5432", member.ExposedPostgresPort)},
 				Environment: map[string]string{
-					"POSTGRES_PASSWORD": "f1refly",
+					"POSTGRES_PASSWORD": s.GetPostgresPassword(),
 					"PGDATA":            "/var/lib/postgresql/data/pgdata",
 				},
 				Volumes: []string{fmt.Sprintf("postgres_%s:/var/lib/postgresql/data", member.ID)},
@@ -125,39 +140,67 @@ func CreateDockerCompose(s *types.Stack) *DockerComposeConfig {
 				service.DependsOn["postgres_"+member.ID] = map[string]string{"condition": "service_healthy"}
 			}
 		}
+	}
 
-		compose.Services["ipfs_"+member.ID] = &Service{
-			Image:         constants.IPFSImageName,
-			ContainerName: fmt.Sprintf("%s_ipfs_%s", s.Name, member.ID),
-			Ports: []string{
-				fmt.Sprintf("%d:5001", member.ExposedIPFSApiPort),
-				fmt.Sprintf("%d:8080", member.ExposedIPFSGWPort),
-			},
+	// A single server hosts a database for each member, which are created by an init script the first time the server starts
+	if s.Database == "postgres" && s.SharedDatabase {
+		compose.Services["postgres"] = &Service{
+			Image:         constants.PostgresImageName,
+			ContainerName: fmt.Sprintf("%s_postgres", s.Name),
+			Ports:         []string{fmt.Sprintf("%d:5432", s.Members[0].ExposedPostgresPort)},
 			Environment: map[string]string{
-				"IPFS_SWARM_KEY":    s.SwarmKey,
-				"LIBP2P_FORCE_PNET": "1",
+				"POSTGRES_PASSWORD": s.GetPostgresPassword(),
+				"PGDATA":            "/var/lib/postgresql/data/pgdata",
 			},
 			Volumes: []string{
-				fmt.Sprintf("ipfs_staging_%s:/export", member.ID),
-				fmt.Sprintf("ipfs_data_%s:/data/ipfs", member.ID),
+				"postgres:/var/lib/postgresql/data",
+				fmt.Sprintf("%s:/docker-entrypoint-initdb.d/firefly.sql", filepath.Join(constants.StacksDir, s.Name, "configs", "postgres_init.sql")),
+			},
+			HealthCheck: &HealthCheck{
+				// The server only listens on TCP once the init script has finished
+				Test:     []string{"CMD-SHELL", "pg_isready -U postgres -h 127.0.0.1"},
+				Interval: "5s",
+				Timeout:  "3s",
+				Retries:  12,
 			},
 			Logging: StandardLogOptions,
 		}
 
-		compose.Volumes[fmt.Sprintf("ipfs_staging_%s", member.ID)] = struct{}{}
-		compose.Volumes[fmt.Sprintf("ipfs_data_%s", member.ID)] = struct{}{}
+		compose.Volumes["postgres"] = struct{}{}
 
-		compose.Services["dataexchange_"+member.ID] = &Service{
-			Image:         s.VersionManifest.DataExchange.GetDockerImageString(),
-			ContainerName: fmt.Sprintf("%s_dataexchange_%s", s.Name, member.ID),
-			Ports:         []string{fmt.Sprintf("%d:3000", member.ExposedDataexchangePort)},
-			Volumes:       []string{fmt.Sprintf("dataexchange_%s:/data", member.ID)},
-			Logging:       StandardLogOptions,
+		for _, member := range s.Members {
+			if service, ok := compose.Services[fmt.Sprintf("firefly_core_%s", member.ID)]; ok {
+				service.DependsOn["postgres"] = map[string]string{"condition": "service_healthy"}
+			}
 		}
+	}
 
-		compose.Volumes[fmt.Sprintf("dataexchange_%s", member.ID)] = struct{}{}
+	return compose
+}
 
+// ApplyResourceLimits sets the resource limits and restart policy of a service, unless the service already
+// sets its own
+func ApplyResourceLimits(service *Service, limits *ResourceLimits) {
+	if service.MemLimit == "" {
+		service.MemLimit = limits.MemLimit
+	}
+	if service.CPUs == "" {
+		service.CPUs = limits.CPUs
 	}
+	if service.Restart == "" {
+		service.Restart = limits.Restart
+	}
+}
 
-	return compose
+// AddExternalNetwork connects every service in the compose file to a docker network that was created outside of
+// the stack, so that the stack's containers can reach other containers on that network by name. The services
+// stay on the stack's default network as well
+func AddExternalNetwork(compose *DockerComposeConfig, network string) {
+	if compose.Networks == nil {
+		compose.Networks = make(map[string]*NetworkConfig)
+	}
+	compose.Networks[network] = &NetworkConfig{External: true}
+	for _, service := range compose.Services {
+		service.Networks = append(service.Networks, "default", network)
+	}
 }
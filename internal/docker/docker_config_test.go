@@ -0,0 +1,55 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestApplyResourceLimits(T *testing.T) {
+	service := &Service{}
+	ApplyResourceLimits(service, &ResourceLimits{MemLimit: "512m", CPUs: "0.5", Restart: "unless-stopped"})
+	assert.Equal(T, &Service{MemLimit: "512m", CPUs: "0.5", Restart: "unless-stopped"}, service)
+
+	service = &Service{MemLimit: "2g", Restart: "always"}
+	ApplyResourceLimits(service, &ResourceLimits{MemLimit: "512m", CPUs: "0.5", Restart: "unless-stopped"})
+	assert.Equal(T, &Service{MemLimit: "2g", CPUs: "0.5", Restart: "always"}, service)
+
+	// The default profile sets no limits
+	ApplyResourceLimits(service, &ResourceLimits{})
+	assert.Equal(T, &Service{MemLimit: "2g", CPUs: "0.5", Restart: "always"}, service)
+}
+
+func TestAddExternalNetwork(T *testing.T) {
+	compose := &DockerComposeConfig{
+		Services: map[string]*Service{
+			"firefly_core_0": {},
+			"ipfs_0":         {},
+		},
+	}
+	AddExternalNetwork(compose, "shared")
+	assert.Equal(T, map[string]*NetworkConfig{"shared": {External: true}}, compose.Networks)
+	assert.Equal(T, []string{"default", "shared"}, compose.Services["firefly_core_0"].Networks)
+	assert.Equal(T, []string{"default", "shared"}, compose.Services["ipfs_0"].Networks)
+
+	bytes, err := yaml.Marshal(compose)
+	assert.NoError(T, err)
+	assert.Contains(T, string(bytes), "networks:\n  shared:\n    external: true\n")
+}
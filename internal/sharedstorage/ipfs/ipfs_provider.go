@@ -0,0 +1,263 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+type IPFSSwarmPeers struct {
+	Peers []*IPFSSwarmPeer `json:"Peers"`
+}
+
+type IPFSSwarmPeer struct {
+	Addr string `json:"Addr"`
+	Peer string `json:"Peer"`
+}
+
+type ipfsPeer struct {
+	ID    string   `json:"ID"`
+	Addrs []string `json:"Addrs"`
+}
+
+// The IPFS image runs scripts in /container-init.d each time the container starts, before starting the daemon
+const ipfsInitScript = `#!/bin/sh
+set -e
+sed -i -e 's|"PeerID": "[^"]*"|"PeerID": "%s"|' -e 's|"PrivKey": "[^"]*"|"PrivKey": "%s"|' "$IPFS_PATH/config"
+ipfs bootstrap rm --all
+%sipfs config --json Peering.Peers '%s'
+`
+
+// IPFSProvider runs a private IPFS node for each member, peered with the other members' nodes
+type IPFSProvider struct {
+	Log     log.Logger
+	Verbose bool
+	Stack   *types.Stack
+}
+
+func (p *IPFSProvider) WriteConfig() error {
+	for _, member := range p.Stack.Members {
+		// Stacks created before IPFS identities were generated keep the identity created by the container
+		if member.IPFSPeerID == "" {
+			continue
+		}
+		script, err := p.GenerateInitScript(member)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p.getInitScriptPath(member), []byte(script), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *IPFSProvider) FirstTimeSetup() error {
+	return p.CopyInitScripts()
+}
+
+// CopyInitScripts copies each member's init script into the volume the IPFS container runs init scripts from
+func (p *IPFSProvider) CopyInitScripts() error {
+	for _, member := range p.Stack.Members {
+		if member.IPFSPeerID == "" {
+			continue
+		}
+		volumeName := fmt.Sprintf("%s_ipfs_init_%s", p.Stack.Name, member.ID)
+		if err := docker.CopyFileToVolume(volumeName, p.getInitScriptPath(member), "/001-firefly.sh", p.Verbose); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostStart waits for each IPFS node to connect to the other nodes in the stack, so that data broadcast by one
+// member can be retrieved by the others. Members on other machines may not be running, so they are not waited for
+func (p *IPFSProvider) PostStart() error {
+	for _, member := range p.Stack.Members {
+		if member.IPFSPeerID == "" {
+			continue
+		}
+		expected := map[string]string{}
+		for _, m := range p.Stack.Members {
+			if m.ID != member.ID && m.IPFSPeerID != "" {
+				expected[m.IPFSPeerID] = "ipfs_" + m.ID
+			}
+		}
+		p.Log.Info(fmt.Sprintf("checking ipfs_%s is connected to its peers", member.ID))
+		var connected map[string]bool
+		var err error
+		for retries := 30; ; retries-- {
+			if connected, err = getConnectedPeers(member); err == nil && len(getMissingPeers(connected, expected)) == 0 {
+				break
+			}
+			if retries == 0 {
+				if err != nil {
+					return fmt.Errorf("unable to query the peers of ipfs_%s: %s", member.ID, err)
+				}
+				return fmt.Errorf("ipfs_%s is not connected to %v", member.ID, getMissingPeers(connected, expected))
+			}
+			time.Sleep(time.Second)
+		}
+		for _, m := range p.Stack.RemoteMembers {
+			if m.IPFSPeerID != "" && !connected[m.IPFSPeerID] {
+				p.Log.Warn(fmt.Sprintf("ipfs_%s is not connected to the IPFS node of remote org '%s'", member.ID, m.OrgName))
+			}
+		}
+	}
+	return nil
+}
+
+func (p *IPFSProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	serviceDefinitions := make([]*docker.ServiceDefinition, 0, len(p.Stack.Members))
+	for _, member := range p.Stack.Members {
+		service := &docker.Service{
+			Image:         constants.IPFSImageName,
+			ContainerName: fmt.Sprintf("%s_ipfs_%s", p.Stack.Name, member.ID),
+			Ports: []string{
+				fmt.Sprintf("%d:5001", member.ExposedIPFSApiPort),
+				fmt.Sprintf("%d:8080", member.ExposedIPFSGWPort),
+			},
+			Environment: map[string]string{
+				"IPFS_SWARM_KEY":    p.Stack.SwarmKey,
+				"LIBP2P_FORCE_PNET": "1",
+			},
+			Volumes: []string{
+				fmt.Sprintf("ipfs_staging_%s:/export", member.ID),
+				fmt.Sprintf("ipfs_data_%s:/data/ipfs", member.ID),
+			},
+			Logging: docker.StandardLogOptions,
+		}
+		volumeNames := []string{"ipfs_staging_" + member.ID, "ipfs_data_" + member.ID}
+		// The init script sets the node's identity and peers, and is copied into its volume the first time the stack starts
+		if member.IPFSPeerID != "" {
+			service.Volumes = append(service.Volumes, fmt.Sprintf("ipfs_init_%s:/container-init.d", member.ID))
+			volumeNames = append(volumeNames, "ipfs_init_"+member.ID)
+		}
+		// Members on other machines connect to the swarm port of each member's node
+		if p.Stack.Host != "" {
+			service.Ports = append(service.Ports, fmt.Sprintf("%d:4001", member.ExposedIPFSSwarmPort))
+		}
+		serviceDefinitions = append(serviceDefinitions, &docker.ServiceDefinition{
+			ServiceName: "ipfs_" + member.ID,
+			Service:     service,
+			VolumeNames: volumeNames,
+		})
+	}
+	return serviceDefinitions
+}
+
+func (p *IPFSProvider) GetFireflyConfig(m *types.Member) *core.PublicStorageConfig {
+	apiURL := fmt.Sprintf("http://ipfs_%s:5001", m.ID)
+	gatewayURL := fmt.Sprintf("http://ipfs_%s:8080", m.ID)
+	if m.External {
+		apiURL = fmt.Sprintf("http://127.0.0.1:%v", m.ExposedIPFSApiPort)
+		gatewayURL = fmt.Sprintf("http://127.0.0.1:%v", m.ExposedIPFSGWPort)
+	}
+	return getFireflyConfig(apiURL, gatewayURL)
+}
+
+// GenerateInitScript returns a script that sets the member's IPFS identity, and configures every other
+// node in the network as both a bootstrap node and a peer that the node stays connected to
+func (p *IPFSProvider) GenerateInitScript(member *types.Member) (string, error) {
+	peers := p.getPeers(member)
+	bootstrap := ""
+	for _, peer := range peers {
+		bootstrap += fmt.Sprintf("ipfs bootstrap add %s/p2p/%s\n", peer.Addrs[0], peer.ID)
+	}
+	peersJSON, err := json.Marshal(peers)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(ipfsInitScript, member.IPFSPeerID, member.IPFSPrivateKey, bootstrap, peersJSON), nil
+}
+
+func (p *IPFSProvider) getPeers(member *types.Member) []*ipfsPeer {
+	peers := []*ipfsPeer{}
+	for _, m := range p.Stack.Members {
+		if m.ID != member.ID && m.IPFSPeerID != "" {
+			peers = append(peers, &ipfsPeer{ID: m.IPFSPeerID, Addrs: []string{fmt.Sprintf("/dns4/ipfs_%s/tcp/4001", m.ID)}})
+		}
+	}
+	for _, m := range p.Stack.RemoteMembers {
+		if m.IPFSPeerID != "" && m.IPFSSwarmAddress != "" {
+			peers = append(peers, &ipfsPeer{ID: m.IPFSPeerID, Addrs: []string{m.IPFSSwarmAddress}})
+		}
+	}
+	return peers
+}
+
+func (p *IPFSProvider) getInitScriptPath(member *types.Member) string {
+	return filepath.Join(constants.StacksDir, p.Stack.Name, "configs", fmt.Sprintf("ipfs_%s.sh", member.ID))
+}
+
+func getMissingPeers(connected map[string]bool, expected map[string]string) []string {
+	missing := []string{}
+	for peerID, name := range expected {
+		if !connected[peerID] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// getConnectedPeers returns the IDs of the peers an IPFS node is currently connected to
+func getConnectedPeers(member *types.Member) (map[string]bool, error) {
+	// The IPFS API only accepts POST requests
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/api/v0/swarm/peers", member.ExposedIPFSApiPort), "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, errors.New(resp.Status)
+	}
+	var peers *IPFSSwarmPeers
+	if err := json.NewDecoder(resp.Body).Decode(&peers); err != nil {
+		return nil, err
+	}
+	connected := make(map[string]bool, len(peers.Peers))
+	for _, peer := range peers.Peers {
+		connected[peer.Peer] = true
+	}
+	return connected, nil
+}
+
+func getFireflyConfig(apiURL string, gatewayURL string) *core.PublicStorageConfig {
+	return &core.PublicStorageConfig{
+		Type: "ipfs",
+		IPFS: &core.FireflyIPFSConfig{
+			API: &core.HttpEndpointConfig{
+				URL: apiURL,
+			},
+			Gateway: &core.HttpEndpointConfig{
+				URL: gatewayURL,
+			},
+		},
+	}
+}
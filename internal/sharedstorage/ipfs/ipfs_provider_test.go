@@ -0,0 +1,46 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateInitScript(T *testing.T) {
+	p := &IPFSProvider{
+		Stack: &types.Stack{
+			Members: []*types.Member{
+				{ID: "0", IPFSPeerID: "peer0", IPFSPrivateKey: "key0"},
+				{ID: "1", IPFSPeerID: "peer1", IPFSPrivateKey: "key1"},
+			},
+			RemoteMembers: []*types.RemoteMember{
+				{OrgName: "org_2", IPFSPeerID: "peer2", IPFSSwarmAddress: "/ip4/192.168.1.10/tcp/5110"},
+			},
+		},
+	}
+	script, err := p.GenerateInitScript(p.Stack.Members[0])
+	assert.NoError(T, err)
+	assert.Contains(T, script, `"PeerID": "peer0"`)
+	assert.Contains(T, script, `"PrivKey": "key0"`)
+	assert.Contains(T, script, "ipfs bootstrap add /dns4/ipfs_1/tcp/4001/p2p/peer1\n")
+	assert.Contains(T, script, "ipfs bootstrap add /ip4/192.168.1.10/tcp/5110/p2p/peer2\n")
+	assert.False(T, strings.Contains(script, "/p2p/peer0"))
+}
@@ -0,0 +1,82 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipfs
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// SharedIPFSProvider runs a single IPFS node that every member uses, which saves resources when
+// members do not need to be isolated from each other. Its ports are the first member's IPFS ports
+type SharedIPFSProvider struct {
+	Log     log.Logger
+	Verbose bool
+	Stack   *types.Stack
+}
+
+func (p *SharedIPFSProvider) WriteConfig() error {
+	return nil
+}
+
+func (p *SharedIPFSProvider) FirstTimeSetup() error {
+	return nil
+}
+
+func (p *SharedIPFSProvider) PostStart() error {
+	return nil
+}
+
+func (p *SharedIPFSProvider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	member := p.Stack.Members[0]
+	return []*docker.ServiceDefinition{
+		{
+			ServiceName: "ipfs",
+			Service: &docker.Service{
+				Image:         constants.IPFSImageName,
+				ContainerName: fmt.Sprintf("%s_ipfs", p.Stack.Name),
+				Ports: []string{
+					fmt.Sprintf("%d:5001", member.ExposedIPFSApiPort),
+					fmt.Sprintf("%d:8080", member.ExposedIPFSGWPort),
+				},
+				Environment: map[string]string{
+					"IPFS_SWARM_KEY":    p.Stack.SwarmKey,
+					"LIBP2P_FORCE_PNET": "1",
+				},
+				Volumes: []string{
+					"ipfs_staging:/export",
+					"ipfs_data:/data/ipfs",
+				},
+				Logging: docker.StandardLogOptions,
+			},
+			VolumeNames: []string{"ipfs_staging", "ipfs_data"},
+		},
+	}
+}
+
+func (p *SharedIPFSProvider) GetFireflyConfig(m *types.Member) *core.PublicStorageConfig {
+	if m.External {
+		member := p.Stack.Members[0]
+		return getFireflyConfig(fmt.Sprintf("http://127.0.0.1:%v", member.ExposedIPFSApiPort), fmt.Sprintf("http://127.0.0.1:%v", member.ExposedIPFSGWPort))
+	}
+	return getFireflyConfig("http://ipfs:5001", "http://ipfs:8080")
+}
@@ -0,0 +1,110 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/log"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// The store is only reachable from the local machine, so fixed development credentials are used,
+// in the same way as the postgres password
+const (
+	accessKeyID     = "firefly"
+	secretAccessKey = "f1reflyS3"
+	bucketName      = "firefly"
+	region          = "us-east-1"
+)
+
+// S3Provider runs a single MinIO server that every member shares as an S3-compatible object store.
+// The API and console are exposed on the first member's shared storage ports.
+//
+// FireFly core releases up to and including v1.0 only include the ipfs shared storage plugin, so this provider
+// needs a core image that was built with an s3 plugin
+type S3Provider struct {
+	Log     log.Logger
+	Verbose bool
+	Stack   *types.Stack
+}
+
+func (p *S3Provider) WriteConfig() error {
+	return nil
+}
+
+func (p *S3Provider) FirstTimeSetup() error {
+	return nil
+}
+
+// PostStart creates the bucket that FireFly stores data in. The MinIO client runs on the stack's
+// network, and is retried until the server has started
+func (p *S3Provider) PostStart() error {
+	p.Log.Info(fmt.Sprintf("creating s3 bucket '%s'", bucketName))
+	return docker.RunDockerCommandRetry(constants.StacksDir, p.Verbose, p.Verbose, 10, "run", "--rm",
+		fmt.Sprintf("--network=%s_default", p.Stack.Name),
+		"--entrypoint", "/bin/sh",
+		constants.MinioClientImageName,
+		"-c", fmt.Sprintf("mc alias set firefly http://s3:9000 %s %s && mc mb --ignore-existing firefly/%s", accessKeyID, secretAccessKey, bucketName),
+	)
+}
+
+func (p *S3Provider) GetDockerServiceDefinitions() []*docker.ServiceDefinition {
+	member := p.Stack.Members[0]
+	return []*docker.ServiceDefinition{
+		{
+			ServiceName: "s3",
+			Service: &docker.Service{
+				Image:         constants.MinioImageName,
+				ContainerName: fmt.Sprintf("%s_s3", p.Stack.Name),
+				Command:       "server /data --console-address :9001",
+				Ports: []string{
+					fmt.Sprintf("%d:9000", member.ExposedIPFSApiPort),
+					fmt.Sprintf("%d:9001", member.ExposedIPFSGWPort),
+				},
+				Environment: map[string]string{
+					"MINIO_ROOT_USER":     accessKeyID,
+					"MINIO_ROOT_PASSWORD": secretAccessKey,
+				},
+				Volumes: []string{"s3:/data"},
+				Logging: docker.StandardLogOptions,
+			},
+			VolumeNames: []string{"s3"},
+		},
+	}
+}
+
+func (p *S3Provider) GetFireflyConfig(m *types.Member) *core.PublicStorageConfig {
+	endpoint := "http://s3:9000"
+	if m.External {
+		endpoint = fmt.Sprintf("http://127.0.0.1:%v", p.Stack.Members[0].ExposedIPFSApiPort)
+	}
+	return &core.PublicStorageConfig{
+		Type: "s3",
+		S3: &core.FireflyS3Config{
+			Endpoint:        endpoint,
+			Bucket:          bucketName,
+			Region:          region,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			ForcePathStyle:  true,
+		},
+	}
+}
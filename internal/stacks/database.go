@@ -0,0 +1,109 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// OpenDatabaseShell starts an interactive psql or sqlite3 session against a member's database
+func (s *StackManager) OpenDatabaseShell(member *types.Member, verbose bool) error {
+	command, err := s.getDatabaseCommand(member, true)
+	if err != nil {
+		return err
+	}
+	return docker.RunDockerCommandInteractive(constants.StacksDir, verbose, command...)
+}
+
+// QueryDatabase runs a single SQL statement against a member's database, and returns the names
+// of the columns along with each row of the result
+func (s *StackManager) QueryDatabase(member *types.Member, query string, verbose bool) (columns []string, rows [][]string, err error) {
+	command, err := s.getDatabaseCommand(member, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Both shells print the result as CSV with a header row, which is easier to parse than their table output
+	switch s.Stack.Database {
+	case PostgreSQL.String():
+		command = append(command, "--csv", "-c", query)
+	case SQLite3.String():
+		command = append(command, "-csv", "-header", query)
+	}
+	output, err := docker.RunDockerCommandStdout(constants.StacksDir, verbose, command...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseQueryResult(output)
+}
+
+// parseQueryResult splits the CSV output of a database shell into the header row and the rows that follow it
+func parseQueryResult(output string) (columns []string, rows [][]string, err error) {
+	if strings.TrimSpace(output) == "" {
+		return []string{}, [][]string{}, nil
+	}
+	reader := csv.NewReader(strings.NewReader(output))
+	// Statements that don't return rows print a single status line, such as "UPDATE 1"
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	return records[0], records[1:], nil
+}
+
+// getDatabaseCommand returns the docker command that runs the database shell for a member. Postgres shells run
+// inside the postgres container, and SQLite shells run in a new container with the database file mounted
+func (s *StackManager) getDatabaseCommand(member *types.Member, interactive bool) ([]string, error) {
+	command := []string{}
+	switch s.Stack.Database {
+	case PostgreSQL.String():
+		containerName := fmt.Sprintf("%s_postgres_%s", s.Stack.Name, member.ID)
+		if s.Stack.SharedDatabase {
+			containerName = fmt.Sprintf("%s_postgres", s.Stack.Name)
+		}
+		command = append(command, "exec")
+		if interactive {
+			command = append(command, "-it")
+		}
+		command = append(command, containerName, "psql", core.GetPostgresURL(s.Stack, member, false))
+	case SQLite3.String():
+		command = append(command, "run", "--rm")
+		if interactive {
+			command = append(command, "-it")
+		}
+		// Strip the connection options from the path
+		dbPath := strings.Split(core.GetSQLitePath(member, s.Stack.Name), "?")[0]
+		if member.External {
+			command = append(command, "-v", fmt.Sprintf("%s:/data", filepath.Dir(dbPath)))
+			dbPath = "/data/" + filepath.Base(dbPath)
+		} else {
+			command = append(command, "-v", fmt.Sprintf("%s_firefly_core_%s:/etc/firefly", s.Stack.Name, member.ID))
+		}
+		command = append(command, "--entrypoint", "sqlite3", constants.SQLiteImageName, dbPath)
+	default:
+		return nil, fmt.Errorf("stack '%s' uses the unsupported database '%s'", s.Stack.Name, s.Stack.Database)
+	}
+	return command, nil
+}
@@ -0,0 +1,103 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric/fabconnect"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+type RegisterIdentityOptions struct {
+	Type           string
+	MaxEnrollments int
+	Attributes     map[string]string
+}
+
+// RegisterIdentity registers a new identity with the Fabric CA through the member's fabconnect,
+// and returns the enrollment secret
+func (s *StackManager) RegisterIdentity(memberID string, name string, options *RegisterIdentityOptions) (string, error) {
+	fabconnectUrl, err := s.getFabconnectUrl(memberID)
+	if err != nil {
+		return "", err
+	}
+	res, err := fabconnect.RegisterIdentity(fabconnectUrl, &fabconnect.CreateIdentityRequest{
+		Name:           name,
+		Type:           options.Type,
+		MaxEnrollments: options.MaxEnrollments,
+		Attributes:     options.Attributes,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Secret, nil
+}
+
+// EnrollIdentity enrolls a registered identity, requesting that the given attributes are included in its certificate
+func (s *StackManager) EnrollIdentity(memberID string, name string, secret string, attributes []string) error {
+	fabconnectUrl, err := s.getFabconnectUrl(memberID)
+	if err != nil {
+		return err
+	}
+	request := &fabconnect.EnrollIdentityRequest{
+		Secret:     secret,
+		Attributes: make(map[string]bool, len(attributes)),
+	}
+	for _, attribute := range attributes {
+		request.Attributes[attribute] = false
+	}
+	_, err = fabconnect.EnrollIdentityWithAttributes(fabconnectUrl, name, request)
+	return err
+}
+
+func (s *StackManager) ListIdentities(memberID string) ([]*fabconnect.Identity, error) {
+	fabconnectUrl, err := s.getFabconnectUrl(memberID)
+	if err != nil {
+		return nil, err
+	}
+	return fabconnect.ListIdentities(fabconnectUrl)
+}
+
+func (s *StackManager) RevokeIdentity(memberID string, name string, reason string) (*fabconnect.RevokeIdentityResponse, error) {
+	fabconnectUrl, err := s.getFabconnectUrl(memberID)
+	if err != nil {
+		return nil, err
+	}
+	return fabconnect.RevokeIdentity(fabconnectUrl, name, &fabconnect.RevokeIdentityRequest{Reason: reason})
+}
+
+func (s *StackManager) getFabconnectUrl(memberID string) (string, error) {
+	if s.Stack.BlockchainProvider != HyperledgerFabric.String() && s.Stack.BlockchainProvider != FabricRemote.String() {
+		return "", fmt.Errorf("stack '%s' uses the '%s' blockchain provider - identities can only be managed on fabric stacks", s.Stack.Name, s.Stack.BlockchainProvider)
+	}
+	member, err := s.GetMember(memberID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("http://127.0.0.1:%v", member.ExposedConnectorPort), nil
+}
+
+// GetMember looks up a member by its ID, or by its org name
+func (s *StackManager) GetMember(memberID string) (*types.Member, error) {
+	for _, member := range s.Stack.Members {
+		if member.ID == memberID || member.OrgName == memberID {
+			return member, nil
+		}
+	}
+	return nil, fmt.Errorf("member '%s' does not exist in stack '%s'", memberID, s.Stack.Name)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/docker"
+)
+
+// loadConfigOverrides reads the FireFly config overrides for the stack and for individual members. They are
+// saved in the stack, so they are applied again whenever the members' configs are generated
+func (s *StackManager) loadConfigOverrides(fireflyConfigPath string, memberConfigPaths map[string]string) (err error) {
+	if fireflyConfigPath != "" {
+		if s.Stack.FireFlyConfigOverrides, err = core.ReadFireflyConfigOverrides(fireflyConfigPath); err != nil {
+			return err
+		}
+	}
+	for id, configPath := range memberConfigPaths {
+		member, err := s.GetMember(id)
+		if err != nil {
+			return err
+		}
+		if member.ConfigOverrides, err = core.ReadFireflyConfigOverrides(configPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateConfigOverrides replaces the saved FireFly config overrides for the stack or for individual members,
+// and generates each member's config again. Hand edits to the generated configs are lost
+func (s *StackManager) UpdateConfigOverrides(fireflyConfigPath string, memberConfigPaths map[string]string) error {
+	if err := s.loadConfigOverrides(fireflyConfigPath, memberConfigPaths); err != nil {
+		return err
+	}
+	if err := s.writeFireflyConfigs(); err != nil {
+		return err
+	}
+	return s.writeStackConfig()
+}
+
+// ApplyFireflyConfigs copies each member's config into its FireFly core volume if it has changed, and restarts
+// the core containers that are running so they pick it up. Members outside of docker read the file directly
+func (s *StackManager) ApplyFireflyConfigs(verbose bool) error {
+	if hasRunBefore, err := s.StackHasRunBefore(); err != nil {
+		return err
+	} else if !hasRunBefore {
+		return errors.New("the stack has not been started yet - configs are copied into each member's volume when it is first started")
+	}
+	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	for _, member := range s.Stack.Members {
+		configPath := filepath.Join(workingDir, "configs", fmt.Sprintf("firefly_core_%s.yml", member.ID))
+		if member.External {
+			s.Log.Info(fmt.Sprintf("firefly core %s runs outside of docker - restart it to use %s", member.ID, configPath))
+			continue
+		}
+		config, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			return err
+		}
+		volumeName := fmt.Sprintf("%s_firefly_core_%s", s.Stack.Name, member.ID)
+		current, err := docker.RunDockerCommandStdout(workingDir, verbose, "run", "--rm", "-v", fmt.Sprintf("%s:/data", volumeName), constants.AlpineImageName, "cat", "/data/firefly.core")
+		if err == nil && current == string(config) {
+			s.Log.Info(fmt.Sprintf("config for firefly_core_%s is unchanged", member.ID))
+			continue
+		}
+
+		s.Log.Info(fmt.Sprintf("copying firefly.core to firefly_core_%s", member.ID))
+		if err := docker.CopyFileToVolume(volumeName, configPath, "/firefly.core", verbose); err != nil {
+			return err
+		}
+		containerName := fmt.Sprintf("%s_firefly_core_%s", s.Stack.Name, member.ID)
+		running, err := docker.RunDockerCommandStdout(workingDir, verbose, "inspect", "-f", "{{.State.Running}}", containerName)
+		if err != nil || strings.TrimSpace(running) != "true" {
+			continue
+		}
+		s.Log.Info(fmt.Sprintf("restarting firefly_core_%s", member.ID))
+		if err := docker.RunDockerCommand(workingDir, verbose, verbose, "restart", containerName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
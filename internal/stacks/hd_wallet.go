@@ -0,0 +1,135 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	secp256k1 "github.com/btcsuite/btcd/btcec"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/sha3"
+)
+
+const hardenedKeyStart = 0x80000000
+
+// Member keys are derived using the standard Ethereum BIP-44 path m/44'/60'/0'/0/<index>
+// so that the same mnemonic yields the same addresses here and in any other HD wallet
+var memberKeyPathPrefix = []uint32{hardenedKeyStart + 44, hardenedKeyStart + 60, hardenedKeyStart + 0, 0}
+
+func GenerateMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+func ValidateMnemonic(mnemonic string) error {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return errors.New("invalid mnemonic - please provide a valid BIP-39 mnemonic phrase")
+	}
+	return nil
+}
+
+func DeriveMemberKey(mnemonic string, index int) (*secp256k1.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	path := make([]uint32, 0, len(memberKeyPathPrefix)+1)
+	path = append(path, memberKeyPathPrefix...)
+	path = append(path, uint32(index))
+	for _, childIndex := range path {
+		if key, chainCode, err = deriveChildKey(key, chainCode, childIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	privateKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), key)
+	return privateKey, nil
+}
+
+// ParsePrivateKey validates a hex encoded secp256k1 private key, with or without the 0x prefix,
+// and returns the normalized key along with its Ethereum address
+func ParsePrivateKey(input string) (encodedPrivateKey string, encodedAddress string, err error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return "", "", errors.New("invalid private key - please provide a 32 byte hex encoded private key")
+	}
+	privateKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), keyBytes)
+	encodedPrivateKey, encodedAddress = getEncodedKeyAndAddress(privateKey)
+	return encodedPrivateKey, encodedAddress, nil
+}
+
+func deriveChildKey(key []byte, chainCode []byte, childIndex uint32) ([]byte, []byte, error) {
+	var data []byte
+	if childIndex >= hardenedKeyStart {
+		data = append([]byte{0x00}, key...)
+	} else {
+		_, publicKey := secp256k1.PrivKeyFromBytes(secp256k1.S256(), key)
+		data = publicKey.SerializeCompressed()
+	}
+	indexBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(indexBytes, childIndex)
+	data = append(data, indexBytes...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	curveOrder := secp256k1.S256().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveOrder) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", childIndex)
+	}
+	childKey := il.Add(il, new(big.Int).SetBytes(key))
+	childKey.Mod(childKey, curveOrder)
+	if childKey.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", childIndex)
+	}
+
+	// Keys must always be serialized as 32 bytes, including any leading zeros
+	childKeyBytes := make([]byte, 32)
+	childKey.FillBytes(childKeyBytes)
+	return childKeyBytes, sum[32:], nil
+}
+
+func getEncodedKeyAndAddress(privateKey *secp256k1.PrivateKey) (encodedPrivateKey string, encodedAddress string) {
+	privateKeyBytes := privateKey.Serialize()
+	encodedPrivateKey = "0x" + hex.EncodeToString(privateKeyBytes)
+	// Remove the "04" Suffix byte when computing the address. This byte indicates that it is an uncompressed public key.
+	publicKeyBytes := privateKey.PubKey().SerializeUncompressed()[1:]
+	// Take the hash of the public key to generate the address
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(publicKeyBytes)
+	// Ethereum addresses only use the lower 20 bytes, so toss the rest away
+	encodedAddress = "0x" + hex.EncodeToString(hash.Sum(nil)[12:32])
+	return encodedPrivateKey, encodedAddress
+}
@@ -0,0 +1,55 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveMemberKey(T *testing.T) {
+	privateKey, err := DeriveMemberKey(testMnemonic, 0)
+	assert.NoError(T, err)
+	encodedPrivateKey, encodedAddress := getEncodedKeyAndAddress(privateKey)
+	assert.Equal(T, "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", encodedPrivateKey)
+	assert.Equal(T, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", encodedAddress)
+
+	privateKey, err = DeriveMemberKey(testMnemonic, 1)
+	assert.NoError(T, err)
+	_, encodedAddress = getEncodedKeyAndAddress(privateKey)
+	assert.Equal(T, "0x70997970c51812dc3a010c7d01b50e0d17dc79c8", encodedAddress)
+}
+
+func TestGenerateMnemonic(T *testing.T) {
+	mnemonic, err := GenerateMnemonic()
+	assert.NoError(T, err)
+	assert.NoError(T, ValidateMnemonic(mnemonic))
+	assert.Error(T, ValidateMnemonic("not a valid mnemonic"))
+}
+
+func TestParsePrivateKey(T *testing.T) {
+	encodedPrivateKey, encodedAddress, err := ParsePrivateKey("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	assert.NoError(T, err)
+	assert.Equal(T, "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", encodedPrivateKey)
+	assert.Equal(T, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", encodedAddress)
+
+	_, _, err = ParsePrivateKey("0x1234")
+	assert.Error(T, err)
+}
@@ -17,10 +17,14 @@
 package stacks
 
 import (
+	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 
+	secp256k1 "github.com/btcsuite/btcd/btcec"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/peer"
 )
@@ -32,11 +36,22 @@ func GenerateSwarmKey() string {
 	return "/key/swarm/psk/1.0.0/\n/base16/\n" + hexKey
 }
 
-func GenerateKeyAndPeerId() (privateKey string, peerId string) {
-	privKey, publicKey, _ := crypto.GenerateKeyPair(crypto.Ed25519, 2048)
-	privateKeyBytes, _ := privKey.Bytes()
-	privateKey = base64.StdEncoding.EncodeToString(privateKeyBytes)
-	peer, _ := peer.IDFromPublicKey(publicKey)
-	peerId = peer.String()
-	return privateKey, peerId
+// DeriveKeyAndPeerId derives a member's IPFS identity from its Ethereum key, so that a stack created
+// from the same mnemonic gives each member the same peer ID, and no two members share one
+func DeriveKeyAndPeerId(memberKey *secp256k1.PrivateKey) (privateKey string, peerId string, err error) {
+	mac := hmac.New(sha256.New, memberKey.Serialize())
+	mac.Write([]byte("ipfs identity"))
+	privKey, err := crypto.UnmarshalEd25519PrivateKey(ed25519.NewKeyFromSeed(mac.Sum(nil)))
+	if err != nil {
+		return "", "", err
+	}
+	privateKeyBytes, err := crypto.MarshalPrivateKey(privKey)
+	if err != nil {
+		return "", "", err
+	}
+	peer, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(privateKeyBytes), peer.String(), nil
 }
@@ -0,0 +1,41 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveKeyAndPeerId(T *testing.T) {
+	memberKey, _ := DeriveMemberKey(testMnemonic, 0)
+	privateKey, peerID, err := DeriveKeyAndPeerId(memberKey)
+	assert.NoError(T, err)
+	assert.True(T, strings.HasPrefix(peerID, "12D3KooW"))
+
+	samePrivateKey, samePeerID, err := DeriveKeyAndPeerId(memberKey)
+	assert.NoError(T, err)
+	assert.Equal(T, privateKey, samePrivateKey)
+	assert.Equal(T, peerID, samePeerID)
+
+	otherMemberKey, _ := DeriveMemberKey(testMnemonic, 1)
+	_, otherPeerID, err := DeriveKeyAndPeerId(otherMemberKey)
+	assert.NoError(T, err)
+	assert.NotEqual(T, peerID, otherPeerID)
+}
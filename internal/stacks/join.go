@@ -0,0 +1,250 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+
+	secp256k1 "github.com/btcsuite/btcd/btcec"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly-cli/internal/constants"
+	"github.com/hyperledger/firefly-cli/internal/dataexchange/https"
+	"github.com/hyperledger/firefly-cli/internal/sharedstorage/ipfs"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+)
+
+// JoinToken contains everything a stack on another machine needs to join this stack's network. It is
+// shared as base64 encoded JSON, and includes the data exchange certs of every member in the network
+type JoinToken struct {
+	BlockchainProvider     string                 `json:"blockchainProvider"`
+	SwarmKey               string                 `json:"swarmKey"`
+	VersionManifest        *types.VersionManifest `json:"versionManifest"`
+	Genesis                json.RawMessage        `json:"genesis"`
+	Bootnodes              []string               `json:"bootnodes"`
+	FireFlyContractAddress string                 `json:"fireflyContractAddress"`
+	TokenContractAddress   string                 `json:"tokenContractAddress,omitempty"`
+	Members                []*types.RemoteMember  `json:"members"`
+}
+
+func (s *StackManager) CreateJoinToken() (string, error) {
+	if s.Stack.Host == "" {
+		return "", fmt.Errorf("stack '%s' was not created with a --host that members on other machines can reach it at", s.Stack.Name)
+	}
+	if s.Stack.BlockchainProvider != GoEthereum.String() {
+		return "", fmt.Errorf("stack '%s' uses the '%s' blockchain provider - join tokens are only supported for geth stacks", s.Stack.Name, s.Stack.BlockchainProvider)
+	}
+	if s.Stack.SharedStorageProvider != "" && s.Stack.SharedStorageProvider != IPFS.String() {
+		return "", fmt.Errorf("stack '%s' uses the '%s' shared storage provider - join tokens are only supported for stacks with an IPFS node per member", s.Stack.Name, s.Stack.SharedStorageProvider)
+	}
+	if s.Stack.FireFlyContract == nil || s.Stack.FireFlyContract.Address == "" {
+		return "", fmt.Errorf("stack '%s' must be started before other stacks can join it", s.Stack.Name)
+	}
+
+	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	genesis, err := ioutil.ReadFile(filepath.Join(stackDir, "blockchain", "genesis.json"))
+	if err != nil {
+		return "", err
+	}
+	enode, err := getEnode(s.Stack.Ethereum.NodeKey, s.Stack.Host, s.Stack.ExposedBlockchainP2PPort)
+	if err != nil {
+		return "", err
+	}
+
+	token := &JoinToken{
+		BlockchainProvider:     s.Stack.BlockchainProvider,
+		SwarmKey:               s.Stack.SwarmKey,
+		VersionManifest:        s.Stack.VersionManifest,
+		Genesis:                genesis,
+		Bootnodes:              append([]string{enode}, s.Stack.Ethereum.GetBootnodes()...),
+		FireFlyContractAddress: s.Stack.FireFlyContract.Address,
+	}
+	if s.Stack.TokenContract != nil {
+		token.TokenContractAddress = s.Stack.TokenContract.Address
+	}
+	for _, member := range s.Stack.Members {
+		cert, err := ioutil.ReadFile(filepath.Join(https.GetDataDir(s.Stack, member), "cert.pem"))
+		if err != nil {
+			return "", err
+		}
+		token.Members = append(token.Members, &types.RemoteMember{
+			OrgName:              member.OrgName,
+			NodeName:             member.NodeName,
+			Address:              member.Address,
+			DataExchangeEndpoint: https.GetP2PEndpoint(s.Stack, member),
+			DataExchangeCert:     string(cert),
+			IPFSSwarmAddress:     getIPFSSwarmAddress(s.Stack.Host, member.ExposedIPFSSwarmPort),
+			IPFSPeerID:           member.IPFSPeerID,
+			BlockchainRPC:        fmt.Sprintf("http://%s:%d", s.Stack.Host, s.Stack.ExposedBlockchainPort),
+		})
+	}
+	// Include the members this stack joined, so the new stack knows about the whole network
+	token.Members = append(token.Members, s.Stack.RemoteMembers...)
+
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(tokenBytes), nil
+}
+
+func DecodeJoinToken(encodedToken string) (*JoinToken, error) {
+	tokenBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedToken))
+	if err != nil {
+		return nil, errors.New("invalid join token - the token must be base64 encoded")
+	}
+	var token *JoinToken
+	if err := json.Unmarshal(tokenBytes, &token); err != nil {
+		return nil, fmt.Errorf("invalid join token: %s", err)
+	}
+	if token.BlockchainProvider != GoEthereum.String() {
+		return nil, fmt.Errorf("join tokens for the '%s' blockchain provider are not supported", token.BlockchainProvider)
+	}
+	if len(token.Genesis) == 0 || token.VersionManifest == nil || token.FireFlyContractAddress == "" {
+		return nil, errors.New("invalid join token - the token is missing details of the network")
+	}
+	return token, nil
+}
+
+// applyJoinToken configures the stack to run its own blockchain node as a peer of the nodes in the joined
+// network, and to use the joined network's FireFly and token contracts rather than deploying new ones
+func (s *StackManager) applyJoinToken(token *JoinToken) error {
+	var genesis *ethereum.Genesis
+	if err := json.Unmarshal(token.Genesis, &genesis); err != nil || genesis.Config == nil {
+		return errors.New("invalid join token - the genesis block could not be parsed")
+	}
+	s.Stack.SwarmKey = token.SwarmKey
+	s.Stack.RemoteMembers = token.Members
+	s.Stack.Ethereum.ChainID = genesis.Config.ChainId
+	s.Stack.Ethereum.BerlinBlock = genesis.Config.BerlinBlock
+	s.Stack.Ethereum.LondonBlock = genesis.Config.LondonBlock
+	s.Stack.Ethereum.Genesis = token.Genesis
+	s.Stack.Ethereum.Bootnodes = token.Bootnodes
+	s.Stack.Ethereum.FireFlyContractAddress = token.FireFlyContractAddress
+	s.Stack.Ethereum.TokenContractAddress = token.TokenContractAddress
+	return nil
+}
+
+// AcceptJoinToken records the members of a stack that joined this stack's network, given the join token of
+// the new stack, so that this stack's blockchain and IPFS nodes peer with theirs the next time it starts.
+// It returns the number of members that were not already known
+func (s *StackManager) AcceptJoinToken(token *JoinToken) (int, error) {
+	if s.Stack.BlockchainProvider != GoEthereum.String() || s.Stack.FireFlyContract == nil || !strings.EqualFold(s.Stack.FireFlyContract.Address, token.FireFlyContractAddress) {
+		return 0, fmt.Errorf("the join token is not for a stack in the same network as stack '%s'", s.Stack.Name)
+	}
+	added, err := s.addJoinedMembers(token)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.writeStackConfig(); err != nil {
+		return 0, err
+	}
+	// Regenerate the IPFS init scripts, which set each node's peers
+	if err := s.sharedStorageProvider.WriteConfig(); err != nil {
+		return 0, err
+	}
+	if ipfsProvider, ok := s.sharedStorageProvider.(*ipfs.IPFSProvider); ok {
+		if err := ipfsProvider.CopyInitScripts(); err != nil {
+			return 0, err
+		}
+	}
+	if s.Stack.PrepopulateDataExchangePeers {
+		if err := s.dataExchangeProvider.WritePeers(); err != nil {
+			return 0, err
+		}
+	}
+	return added, nil
+}
+
+// addJoinedMembers adds the members and blockchain nodes in a join token that this stack does not already know about
+func (s *StackManager) addJoinedMembers(token *JoinToken) (int, error) {
+	localOrgs := map[string]bool{}
+	for _, member := range s.Stack.Members {
+		localOrgs[member.OrgName] = true
+	}
+	added := 0
+	for _, member := range token.Members {
+		if localOrgs[member.OrgName] {
+			continue
+		}
+		// Replace the details of members that were already known, in case they have changed
+		known := false
+		for i, remoteMember := range s.Stack.RemoteMembers {
+			if remoteMember.OrgName == member.OrgName {
+				s.Stack.RemoteMembers[i] = member
+				known = true
+				break
+			}
+		}
+		if !known {
+			s.Stack.RemoteMembers = append(s.Stack.RemoteMembers, member)
+			added++
+		}
+	}
+
+	enode := ""
+	if s.Stack.Host != "" && s.Stack.Ethereum.NodeKey != "" {
+		var err error
+		if enode, err = getEnode(s.Stack.Ethereum.NodeKey, s.Stack.Host, s.Stack.ExposedBlockchainP2PPort); err != nil {
+			return 0, err
+		}
+	}
+	for _, bootnode := range token.Bootnodes {
+		known := bootnode == enode
+		for _, existing := range s.Stack.Ethereum.Bootnodes {
+			known = known || existing == bootnode
+		}
+		if !known {
+			s.Stack.Ethereum.Bootnodes = append(s.Stack.Ethereum.Bootnodes, bootnode)
+		}
+	}
+	return added, nil
+}
+
+func generateNodeKey() (string, error) {
+	privateKey, err := secp256k1.NewPrivateKey(secp256k1.S256())
+	if err != nil {
+		return "", err
+	}
+	encodedPrivateKey, _ := getEncodedKeyAndAddress(privateKey)
+	return encodedPrivateKey, nil
+}
+
+// getEnode returns the enode URL of a geth node, which is made up of the node's public key and its p2p address
+func getEnode(nodeKey string, host string, port int) (string, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(nodeKey, "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return "", errors.New("invalid node key")
+	}
+	_, publicKey := secp256k1.PrivKeyFromBytes(secp256k1.S256(), keyBytes)
+	// The node ID is the uncompressed public key without the leading "04" byte
+	return fmt.Sprintf("enode://%s@%s:%d", hex.EncodeToString(publicKey.SerializeUncompressed()[1:]), host, port), nil
+}
+
+func getIPFSSwarmAddress(host string, port int) string {
+	if net.ParseIP(host) == nil {
+		return fmt.Sprintf("/dns4/%s/tcp/%d", host, port)
+	}
+	return fmt.Sprintf("/ip4/%s/tcp/%d", host, port)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJoinToken(T *testing.T) {
+	tokenBytes, _ := json.Marshal(&JoinToken{
+		BlockchainProvider:     "geth",
+		SwarmKey:               "/key/swarm/psk/1.0.0/\n/base16/\n00",
+		VersionManifest:        &types.VersionManifest{},
+		Genesis:                json.RawMessage(`{"config":{"chainId":2021}}`),
+		FireFlyContractAddress: "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+		Members:                []*types.RemoteMember{{OrgName: "org_0"}},
+	})
+	token, err := DecodeJoinToken(base64.StdEncoding.EncodeToString(tokenBytes) + "\n")
+	assert.NoError(T, err)
+	assert.Equal(T, "org_0", token.Members[0].OrgName)
+
+	_, err = DecodeJoinToken("not a token")
+	assert.Error(T, err)
+
+	tokenBytes, _ = json.Marshal(&JoinToken{BlockchainProvider: "fabric"})
+	_, err = DecodeJoinToken(base64.StdEncoding.EncodeToString(tokenBytes))
+	assert.Regexp(T, "not supported", err)
+}
+
+func TestGetEnode(T *testing.T) {
+	enode, err := getEnode("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", "192.168.1.10", 5199)
+	assert.NoError(T, err)
+	assert.True(T, strings.HasPrefix(enode, "enode://"))
+	assert.True(T, strings.HasSuffix(enode, "@192.168.1.10:5199"))
+	assert.Len(T, strings.TrimSuffix(strings.TrimPrefix(enode, "enode://"), "@192.168.1.10:5199"), 128)
+}
+
+func TestGetIPFSSwarmAddress(T *testing.T) {
+	assert.Equal(T, "/ip4/192.168.1.10/tcp/5110", getIPFSSwarmAddress("192.168.1.10", 5110))
+	assert.Equal(T, "/dns4/laptop.local/tcp/5110", getIPFSSwarmAddress("laptop.local", 5110))
+}
+
+func TestAddJoinedMembers(T *testing.T) {
+	nodeKey := "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	enode, _ := getEnode(nodeKey, "192.168.1.10", 5199)
+	s := &StackManager{
+		Stack: &types.Stack{
+			Host:                     "192.168.1.10",
+			ExposedBlockchainP2PPort: 5199,
+			Members:                  []*types.Member{{ID: "0", OrgName: "org_0"}},
+			RemoteMembers:            []*types.RemoteMember{{OrgName: "org_1", DataExchangeEndpoint: "https://old:5209"}},
+			Ethereum:                 &types.EthereumOptions{NodeKey: nodeKey},
+		},
+	}
+	token := &JoinToken{
+		Bootnodes: []string{"enode://joined@192.168.1.20:5199", enode},
+		Members: []*types.RemoteMember{
+			{OrgName: "org_2"},
+			{OrgName: "org_1", DataExchangeEndpoint: "https://new:5209"},
+			{OrgName: "org_0"},
+		},
+	}
+	added, err := s.addJoinedMembers(token)
+	assert.NoError(T, err)
+	assert.Equal(T, 1, added)
+	assert.Len(T, s.Stack.RemoteMembers, 2)
+	assert.Equal(T, "https://new:5209", s.Stack.RemoteMembers[0].DataExchangeEndpoint)
+	assert.Equal(T, "org_2", s.Stack.RemoteMembers[1].OrgName)
+	assert.Equal(T, []string{"enode://joined@192.168.1.20:5199"}, s.Stack.Ethereum.Bootnodes)
+
+	added, err = s.addJoinedMembers(token)
+	assert.NoError(T, err)
+	assert.Equal(T, 0, added)
+	assert.Len(T, s.Stack.Ethereum.Bootnodes, 1)
+}
@@ -17,6 +17,7 @@
 package stacks
 
 import (
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -24,26 +25,34 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
+	"unicode"
 
-	secp256k1 "github.com/btcsuite/btcd/btcec"
 	"github.com/hyperledger/firefly-cli/internal/blockchain"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum"
 	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/besu"
 	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/geth"
+	"github.com/hyperledger/firefly-cli/internal/blockchain/ethereum/remoterpc"
 	"github.com/hyperledger/firefly-cli/internal/blockchain/fabric"
 	"github.com/hyperledger/firefly-cli/internal/constants"
 	"github.com/hyperledger/firefly-cli/internal/core"
+	"github.com/hyperledger/firefly-cli/internal/dataexchange"
+	"github.com/hyperledger/firefly-cli/internal/dataexchange/https"
 	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/internal/sharedstorage"
+	"github.com/hyperledger/firefly-cli/internal/sharedstorage/ipfs"
+	"github.com/hyperledger/firefly-cli/internal/sharedstorage/s3"
 	"github.com/hyperledger/firefly-cli/internal/tokens"
 	"github.com/hyperledger/firefly-cli/internal/tokens/erc1155"
 	"github.com/hyperledger/firefly-cli/internal/tokens/niltokens"
 	"github.com/hyperledger/firefly-cli/pkg/types"
-	"golang.org/x/crypto/sha3"
 
 	"gopkg.in/yaml.v2"
 
@@ -51,10 +60,12 @@ import (
 )
 
 type StackManager struct {
-	Log                log.Logger
-	Stack              *types.Stack
-	blockchainProvider blockchain.IBlockchainProvider
-	tokensProvider     tokens.ITokensProvider
+	Log                   log.Logger
+	Stack                 *types.Stack
+	blockchainProvider    blockchain.IBlockchainProvider
+	dataExchangeProvider  dataexchange.IDataExchangeProvider
+	tokensProvider        tokens.ITokensProvider
+	sharedStorageProvider sharedstorage.ISharedStorageProvider
 }
 
 type PullOptions struct {
@@ -66,17 +77,48 @@ type StartOptions struct {
 }
 
 type InitOptions struct {
-	FireFlyBasePort    int
-	ServicesBasePort   int
-	DatabaseSelection  DatabaseSelection
-	Verbose            bool
-	ExternalProcesses  int
-	OrgNames           []string
-	NodeNames          []string
-	BlockchainProvider BlockchainProvider
-	TokensProvider     TokensProvider
-	FireFlyVersion     string
-	ManifestPath       string
+	FireFlyBasePort       int
+	ServicesBasePort      int
+	DatabaseSelection     DatabaseSelection
+	Verbose               bool
+	ExternalProcesses     int
+	OrgNames              []string
+	NodeNames             []string
+	BlockchainProvider    BlockchainProvider
+	TokensProvider        TokensProvider
+	SharedStorageProvider SharedStorageProvider
+	DataExchangeProvider  DataExchangeProvider
+	ResourceProfile       ResourceProfile
+	Network               string
+	UseSavedImages        bool
+	DataExchangePeers     bool
+	SharedDatabase        bool
+	FireFlyConfigPath     string
+	MemberConfigPaths     map[string]string
+	FireFlyVersion        string
+	ManifestPath          string
+	Mnemonic              string
+	ChainID               int
+	BlockPeriod           int
+	GasLimit              uint64
+	BerlinBlock           int
+	London                bool
+	PrefundedAccounts     map[string]string
+	Contracts             []string
+	SolcBasePath          string
+	Channels              []string
+	ChaincodeName         string
+	EndorsementPolicy     string
+	Configtx              string
+	GenesisProfile        string
+	Orderers              int
+	RPCURL                string
+	FundingKey            string
+	ConnectionProfile     string
+	CryptoDir             string
+	FireFlyContract       string
+	Host                  string
+	JoinToken             *JoinToken
 }
 
 func ListStacks() ([]string, error) {
@@ -106,18 +148,44 @@ func NewStackManager(logger log.Logger) *StackManager {
 
 func (s *StackManager) InitStack(stackName string, memberCount int, options *InitOptions) (err error) {
 	s.Stack = &types.Stack{
-		Name:                  stackName,
-		Members:               make([]*types.Member, memberCount),
-		SwarmKey:              GenerateSwarmKey(),
-		ExposedBlockchainPort: options.ServicesBasePort,
-		Database:              options.DatabaseSelection.String(),
-		BlockchainProvider:    options.BlockchainProvider.String(),
-		TokensProvider:        options.TokensProvider.String(),
+		Name:                         stackName,
+		Members:                      make([]*types.Member, memberCount),
+		SwarmKey:                     GenerateSwarmKey(),
+		ExposedBlockchainPort:        options.ServicesBasePort,
+		Database:                     options.DatabaseSelection.String(),
+		SharedDatabase:               options.SharedDatabase,
+		BlockchainProvider:           options.BlockchainProvider.String(),
+		TokensProvider:               options.TokensProvider.String(),
+		SharedStorageProvider:        options.SharedStorageProvider.String(),
+		DataExchangeProvider:         options.DataExchangeProvider.String(),
+		PrepopulateDataExchangePeers: options.DataExchangePeers,
+		ResourceProfile:              options.ResourceProfile.String(),
+		Network:                      options.Network,
+		UseSavedImages:               options.UseSavedImages,
+		Mnemonic:                     options.Mnemonic,
+		Host:                         options.Host,
+	}
+
+	// Generate a new mnemonic if one wasn't provided, and save it in the stack so that
+	// member keys can be derived again if the stack is ever recreated
+	if s.Stack.Mnemonic == "" {
+		if s.Stack.Mnemonic, err = GenerateMnemonic(); err != nil {
+			return err
+		}
+	}
+
+	// A shared database server holds every member's data, so it gets its own password. Stacks with a
+	// server for each member keep the default password
+	if options.DatabaseSelection == PostgreSQL && options.SharedDatabase {
+		s.Stack.PostgresPassword = generatePassword()
 	}
 
 	var manifest *types.VersionManifest
 
-	if options.ManifestPath != "" {
+	if options.JoinToken != nil {
+		// Use the same versions as the network that is being joined
+		manifest = options.JoinToken.VersionManifest
+	} else if options.ManifestPath != "" {
 		// If a path to a manifest file is set, read the existing file
 		manifest, err = core.ReadManifestFile(options.ManifestPath)
 		if err != nil {
@@ -126,53 +194,113 @@ func (s *StackManager) InitStack(stackName string, memberCount int, options *Ini
 	} else {
 		// Otherwise, fetch the manifest file from GitHub for the specified version
 		if options.FireFlyVersion == "" || strings.ToLower(options.FireFlyVersion) == "latest" {
-			manifest, err = core.GetLatestReleaseManifest()
+			manifest, err = core.GetLatestReleaseManifest(s.Log)
 			if err != nil {
 				return err
 			}
 		} else {
-			manifest, err = core.GetReleaseManifest(options.FireFlyVersion)
+			manifest, err = core.GetReleaseManifest(s.Log, options.FireFlyVersion)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	if options.BlockchainProvider == GoEthereum || options.BlockchainProvider == HyperledgerBesu {
+		s.Stack.Ethereum = &types.EthereumOptions{
+			ChainID:           options.ChainID,
+			BlockPeriod:       options.BlockPeriod,
+			GasLimit:          options.GasLimit,
+			PrefundedAccounts: options.PrefundedAccounts,
+		}
+		// London is activated in the genesis block, and requires Berlin to be active there too
+		if options.London {
+			genesisBlock := 0
+			s.Stack.Ethereum.LondonBlock = &genesisBlock
+			s.Stack.Ethereum.BerlinBlock = &genesisBlock
+		}
+		if options.BerlinBlock >= 0 && s.Stack.Ethereum.BerlinBlock == nil {
+			berlinBlock := options.BerlinBlock
+			s.Stack.Ethereum.BerlinBlock = &berlinBlock
+		}
+	}
+
+	if options.BlockchainProvider == GoEthereum && options.Host != "" {
+		// Expose the geth p2p port in the unused range after the first member's ports, so other machines can peer with it
+		s.Stack.ExposedBlockchainP2PPort = options.ServicesBasePort + 99
+		if s.Stack.Ethereum.NodeKey, err = generateNodeKey(); err != nil {
+			return err
+		}
+	}
+
+	if options.JoinToken != nil {
+		if err := s.applyJoinToken(options.JoinToken); err != nil {
+			return err
+		}
+	}
+
+	if options.BlockchainProvider == EthereumRemote {
+		s.Stack.Ethereum = &types.EthereumOptions{
+			RPCURL:                 options.RPCURL,
+			AccountPassword:        generatePassword(),
+			FireFlyContractAddress: options.FireFlyContract,
+		}
+	}
+
+	if options.BlockchainProvider == HyperledgerFabric || options.BlockchainProvider == FabricRemote {
+		s.Stack.Fabric = &types.FabricOptions{
+			Channels:          options.Channels,
+			ChaincodeName:     options.ChaincodeName,
+			EndorsementPolicy: options.EndorsementPolicy,
+			Configtx:          options.Configtx,
+			GenesisProfile:    options.GenesisProfile,
+			Orderers:          options.Orderers,
+			ConnectionProfile: options.ConnectionProfile,
+			CryptoDir:         options.CryptoDir,
+		}
+	}
+
 	s.Stack.VersionManifest = manifest
+	// Contract names are saved before the stack is started, as the FireFly core configs and the token
+	// connectors refer to the contracts by name before they are deployed
+	if options.BlockchainProvider == GoEthereum || options.BlockchainProvider == HyperledgerBesu || options.BlockchainProvider == EthereumRemote {
+		s.Stack.FireFlyContract = ethereum.NewFireFlyContractDeployment(s.Stack)
+	}
+	if options.TokensProvider == ERC1155 {
+		s.Stack.TokenContract = erc1155.NewContractDeployment(s.Stack)
+	}
 	s.blockchainProvider = s.getBlockchainProvider(false)
 	s.tokensProvider = s.getTokensProvider(false)
+	s.sharedStorageProvider = s.getSharedStorageProvider(false)
+	s.dataExchangeProvider = s.getDataExchangeProvider(false)
 
 	for i := 0; i < memberCount; i++ {
 		externalProcess := i < options.ExternalProcesses
-		s.Stack.Members[i] = createMember(fmt.Sprint(i), i, options, externalProcess)
+		if s.Stack.Members[i], err = createMember(fmt.Sprint(i), i, s.Stack.Mnemonic, options, externalProcess); err != nil {
+			return err
+		}
 	}
-	compose := docker.CreateDockerCompose(s.Stack)
-	extraServices := s.blockchainProvider.GetDockerServiceDefinitions()
-	extraServices = append(extraServices, s.tokensProvider.GetDockerServiceDefinitions()...)
-
-	for _, serviceDefinition := range extraServices {
-		// Add each service definition to the docker compose file
-		compose.Services[serviceDefinition.ServiceName] = serviceDefinition.Service
-		// Add the volume name for each volume used by this service
-		for _, volumeName := range serviceDefinition.VolumeNames {
-			compose.Volumes[volumeName] = struct{}{}
+	if options.BlockchainProvider == EthereumRemote && options.FireFlyContract != "" {
+		if err := remoterpc.CheckContract(options.RPCURL, options.FireFlyContract); err != nil {
+			return err
 		}
-
-		// Add a dependency so each firefly core container won't start up until dependencies are up
-		for _, member := range s.Stack.Members {
-			if service, ok := compose.Services[fmt.Sprintf("firefly_core_%v", *member.Index)]; ok {
-				condition := "service_started"
-				if serviceDefinition.Service.HealthCheck != nil {
-					condition = "service_healthy"
-				}
-				service.DependsOn[serviceDefinition.ServiceName] = map[string]string{"condition": condition}
-			}
+	}
+	// The funding key is only used here, and is not saved with the stack
+	if options.BlockchainProvider == EthereumRemote && options.FundingKey != "" {
+		if err := remoterpc.FundMembers(s.Log, remoterpc.GetHostRPCURL(options.RPCURL), options.FundingKey, s.Stack.Members); err != nil {
+			return fmt.Errorf("failed to fund member accounts: %s", err)
 		}
 	}
-
+	if err := s.loadConfigOverrides(options.FireFlyConfigPath, options.MemberConfigPaths); err != nil {
+		return err
+	}
+	compose := s.buildDockerCompose()
 	if err := s.ensureDirectories(); err != nil {
 		return err
 	}
+	if err := s.copyCustomContracts(options.Contracts, options.SolcBasePath, options.Verbose); err != nil {
+		return err
+	}
 	if err := s.writeDockerCompose(compose); err != nil {
 		return fmt.Errorf("failed to write docker-compose.yml: %s", err)
 	}
@@ -209,6 +337,8 @@ func (s *StackManager) LoadStack(stackName string, verbose bool) error {
 		s.Stack = stack
 		s.blockchainProvider = s.getBlockchainProvider(verbose)
 		s.tokensProvider = s.getTokensProvider(verbose)
+		s.sharedStorageProvider = s.getSharedStorageProvider(verbose)
+		s.dataExchangeProvider = s.getDataExchangeProvider(verbose)
 	}
 	// For backwards compatability, add a "default" VersionManifest
 	// in memory for stacks that were created with old CLI versions
@@ -242,16 +372,12 @@ func (s *StackManager) LoadStack(stackName string, verbose bool) error {
 func (s *StackManager) ensureDirectories() error {
 
 	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
-	dataDir := filepath.Join(stackDir, "data")
 
 	if err := os.MkdirAll(filepath.Join(stackDir, "configs"), 0755); err != nil {
 		return err
 	}
 
 	for _, member := range s.Stack.Members {
-		if err := os.MkdirAll(filepath.Join(dataDir, "dataexchange_"+member.ID, "peer-certs"), 0755); err != nil {
-			return err
-		}
 		if err := os.MkdirAll(filepath.Join(stackDir, "blockchain", member.ID), 0755); err != nil {
 			return err
 		}
@@ -259,6 +385,65 @@ func (s *StackManager) ensureDirectories() error {
 	return nil
 }
 
+// buildDockerCompose creates the docker compose config for the stack, including the services of each provider
+func (s *StackManager) buildDockerCompose() *docker.DockerComposeConfig {
+	compose := docker.CreateDockerCompose(s.Stack)
+	blockchainServices := s.blockchainProvider.GetDockerServiceDefinitions()
+	extraServices := blockchainServices
+	extraServices = append(extraServices, s.tokensProvider.GetDockerServiceDefinitions()...)
+	extraServices = append(extraServices, s.sharedStorageProvider.GetDockerServiceDefinitions()...)
+	extraServices = append(extraServices, s.dataExchangeProvider.GetDockerServiceDefinitions()...)
+
+	for _, serviceDefinition := range extraServices {
+		// Add each service definition to the docker compose file
+		compose.Services[serviceDefinition.ServiceName] = serviceDefinition.Service
+		// Add the volume name for each volume used by this service
+		for _, volumeName := range serviceDefinition.VolumeNames {
+			compose.Volumes[volumeName] = struct{}{}
+		}
+
+		// Add a dependency so each firefly core container won't start up until dependencies are up
+		for _, member := range s.Stack.Members {
+			if service, ok := compose.Services[fmt.Sprintf("firefly_core_%v", *member.Index)]; ok {
+				condition := "service_started"
+				if serviceDefinition.Service.HealthCheck != nil {
+					condition = "service_healthy"
+				}
+				service.DependsOn[serviceDefinition.ServiceName] = map[string]string{"condition": condition}
+			}
+		}
+	}
+
+	s.applyResourceLimits(compose, blockchainServices)
+	if s.Stack.UseSavedImages {
+		s.useSavedImages(compose)
+	}
+	if s.Stack.Network != "" {
+		docker.AddExternalNetwork(compose, s.Stack.Network)
+	}
+	return compose
+}
+
+// applyResourceLimits sets the limits of the stack's resource profile on each service, by the type of the service
+func (s *StackManager) applyResourceLimits(compose *docker.DockerComposeConfig, blockchainServices []*docker.ServiceDefinition) {
+	resourceProfile, _ := ResourceProfileFromString(s.Stack.ResourceProfile)
+	limits := resourceProfile.Limits()
+	isBlockchainService := map[string]bool{}
+	for _, serviceDefinition := range blockchainServices {
+		isBlockchainService[serviceDefinition.ServiceName] = true
+	}
+	for serviceName, service := range compose.Services {
+		switch {
+		case isBlockchainService[serviceName]:
+			docker.ApplyResourceLimits(service, limits.Blockchain)
+		case strings.HasPrefix(serviceName, "postgres"):
+			docker.ApplyResourceLimits(service, limits.Database)
+		default:
+			docker.ApplyResourceLimits(service, limits.Default)
+		}
+	}
+}
+
 func (s *StackManager) writeDockerCompose(compose *docker.DockerComposeConfig) error {
 	bytes, err := yaml.Marshal(compose)
 	if err != nil {
@@ -267,26 +452,76 @@ func (s *StackManager) writeDockerCompose(compose *docker.DockerComposeConfig) e
 
 	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
 
-	return ioutil.WriteFile(filepath.Join(stackDir, "docker-compose.yml"), bytes, 0755)
+	if err := ioutil.WriteFile(filepath.Join(stackDir, "docker-compose.yml"), bytes, 0755); err != nil {
+		return err
+	}
+	return docker.SetComposeOverrideVersion(stackDir, compose.Version)
 }
 
-func (s *StackManager) writeConfigs(verbose bool) error {
+// UpdateComposeOverride sets environment variables and adds volumes for one of the stack's services in its
+// docker-compose.override.yml, which is used alongside the generated docker-compose.yml
+func (s *StackManager) UpdateComposeOverride(serviceName string, env []string, volumes []string) error {
 	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	bytes, err := ioutil.ReadFile(filepath.Join(stackDir, "docker-compose.yml"))
+	if err != nil {
+		return err
+	}
+	var compose *docker.DockerComposeConfig
+	if err := yaml.Unmarshal(bytes, &compose); err != nil {
+		return err
+	}
+	if _, ok := compose.Services[serviceName]; !ok {
+		serviceNames := make([]string, 0, len(compose.Services))
+		for name := range compose.Services {
+			serviceNames = append(serviceNames, name)
+		}
+		sort.Strings(serviceNames)
+		return fmt.Errorf("stack '%s' has no service '%s'. valid services are: %v", s.Stack.Name, serviceName, serviceNames)
+	}
+	return docker.UpdateComposeOverride(stackDir, compose.Version, serviceName, env, volumes)
+}
 
+func (s *StackManager) writeFireflyConfigs() error {
+	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
 	for _, member := range s.Stack.Members {
 		config := core.NewFireflyConfig(s.Stack, member)
 		config.Blockchain, config.Org = s.blockchainProvider.GetFireflyConfig(member)
 		config.Tokens = s.tokensProvider.GetFireflyConfig(member)
-		if err := core.WriteFireflyConfig(config, filepath.Join(stackDir, "configs", fmt.Sprintf("firefly_core_%s.yml", member.ID))); err != nil {
+		config.P2PFS = s.sharedStorageProvider.GetFireflyConfig(member)
+		config.DataExchange = s.dataExchangeProvider.GetFireflyConfig(member)
+		overrides := []map[string]interface{}{}
+		if s.Stack.FireFlyConfigOverrides != nil {
+			overrides = append(overrides, s.Stack.FireFlyConfigOverrides)
+		}
+		if member.ConfigOverrides != nil {
+			overrides = append(overrides, member.ConfigOverrides)
+		}
+		if err := core.WriteFireflyConfig(config, filepath.Join(stackDir, "configs", fmt.Sprintf("firefly_core_%s.yml", member.ID)), overrides...); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	stackConfigBytes, _ := json.MarshalIndent(s.Stack, "", " ")
-	if err := ioutil.WriteFile(filepath.Join(stackDir, "stack.json"), stackConfigBytes, 0755); err != nil {
+func (s *StackManager) writeConfigs(verbose bool) error {
+	if err := s.writeFireflyConfigs(); err != nil {
 		return err
 	}
 
+	if err := s.writeStackConfig(); err != nil {
+		return err
+	}
+
+	if err := s.sharedStorageProvider.WriteConfig(); err != nil {
+		return err
+	}
+
+	if s.Stack.Database == PostgreSQL.String() && s.Stack.SharedDatabase {
+		if err := s.writePostgresInitScript(); err != nil {
+			return err
+		}
+	}
+
 	if err := s.blockchainProvider.WriteConfig(); err != nil {
 		return err
 	}
@@ -294,50 +529,132 @@ func (s *StackManager) writeConfigs(verbose bool) error {
 	return nil
 }
 
-func (s *StackManager) writeDataExchangeCerts(verbose bool) error {
-	stackDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+// writePostgresInitScript writes the script that creates each member's database, which the
+// shared PostgreSQL server runs the first time it starts with an empty data volume
+func (s *StackManager) writePostgresInitScript() error {
+	script := ""
 	for _, member := range s.Stack.Members {
+		script += fmt.Sprintf("CREATE DATABASE %s;\n", core.PostgresDatabaseName(member))
+	}
+	return ioutil.WriteFile(filepath.Join(constants.StacksDir, s.Stack.Name, "configs", "postgres_init.sql"), []byte(script), 0755)
+}
 
-		memberDXDir := path.Join(stackDir, "data", "dataexchange_"+member.ID)
+func generatePassword() string {
+	password := make([]byte, 16)
+	rand.Read(password)
+	return hex.EncodeToString(password)
+}
 
-		// TODO: remove dependency on openssl here
-		opensslCmd := exec.Command("openssl", "req", "-new", "-x509", "-nodes", "-days", "365", "-subj", fmt.Sprintf("/CN=dataexchange_%s/O=member_%s", member.ID, member.ID), "-keyout", "key.pem", "-out", "cert.pem")
-		opensslCmd.Dir = filepath.Join(stackDir, "data", "dataexchange_"+member.ID)
-		if err := opensslCmd.Run(); err != nil {
-			return err
+func (s *StackManager) writeStackConfig() error {
+	stackConfigBytes, _ := json.MarshalIndent(s.Stack, "", " ")
+	return ioutil.WriteFile(filepath.Join(constants.StacksDir, s.Stack.Name, "stack.json"), stackConfigBytes, 0755)
+}
+
+// splitContractSpec splits a contract spec in the format <path>[:<name>[:<arg>=<value>,...]] into its parts.
+// The colon after a windows drive letter is part of the path
+func splitContractSpec(spec string) []string {
+	start := 0
+	if len(spec) > 2 && spec[1] == ':' && (spec[2] == '\\' || spec[2] == '/') && unicode.IsLetter(rune(spec[0])) {
+		start = 2
+	}
+	parts := strings.SplitN(spec[start:], ":", 3)
+	parts[0] = spec[:start] + parts[0]
+	return parts
+}
+
+// splitContractSelector splits the path of a contract spec into the file path, and the name of the contract to
+// use from a Solidity file, which follows a '#'
+func splitContractSelector(path string) (string, string) {
+	if i := strings.LastIndex(path, "#"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+// uniqueFilename adds a number to the file name if it is already used, and records it as used
+func uniqueFilename(filename string, used map[string]bool) string {
+	ext := filepath.Ext(filename)
+	unique := filename
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(filename, ext), i, ext)
+	}
+	used[unique] = true
+	return unique
+}
+
+// Contracts are specified in the format <path>[#<contract>][:<name>[:<arg>=<value>,...]]. Each one is copied into
+// the stack directory, so the stack can still be reset and started again if the original file moves
+func (s *StackManager) copyCustomContracts(contractSpecs []string, solcBasePath string, verbose bool) error {
+	contractsDir := filepath.Join(constants.StacksDir, s.Stack.Name, ethereum.CustomContractsDir)
+	names := make(map[string]bool)
+	filenames := make(map[string]bool)
+	for _, spec := range contractSpecs {
+		parts := splitContractSpec(spec)
+		contractPath, contractName := splitContractSelector(parts[0])
+		contract, err := ethereum.ReadContract(contractPath, contractName, solcBasePath, verbose)
+		if err != nil {
+			return fmt.Errorf("failed to read contract '%s': %s", contractPath, err)
+		}
+		if contract.Bytecode == "" {
+			return fmt.Errorf("contract '%s' does not contain any bytecode", contractPath)
 		}
 
-		dataExchangeConfig := s.GenerateDataExchangeHTTPSConfig(member.ID)
-		configBytes, err := json.Marshal(dataExchangeConfig)
+		deployment := &types.ContractDeployment{
+			Filename: filepath.Base(contractPath),
+			Args:     map[string]string{},
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			deployment.Name = parts[1]
+		} else {
+			deployment.Name = ethereum.GetDefaultContractName(contract, deployment.Filename)
+		}
+		if names[deployment.Name] {
+			return fmt.Errorf("more than one contract is named '%s' - please specify a unique name for each contract", deployment.Name)
+		}
+		names[deployment.Name] = true
+		if len(parts) > 2 && parts[2] != "" {
+			for _, arg := range strings.Split(parts[2], ",") {
+				kv := strings.SplitN(arg, "=", 2)
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid argument '%s' for contract '%s' - arguments must be in the format <name>=<value>", arg, deployment.Name)
+				}
+				deployment.Args[kv[0]] = kv[1]
+			}
+		}
+
+		if err := os.MkdirAll(contractsDir, 0755); err != nil {
+			return err
+		}
+		// Solidity sources are compiled once here, and the compiled contract is stored with the stack
+		var contractBytes []byte
+		if strings.ToLower(filepath.Ext(contractPath)) == ".sol" {
+			deployment.Filename = strings.TrimSuffix(deployment.Filename, filepath.Ext(deployment.Filename)) + ".json"
+			contractBytes, err = json.MarshalIndent(contract, "", " ")
+		} else {
+			contractBytes, err = ioutil.ReadFile(contractPath)
+		}
 		if err != nil {
 			return err
 		}
-		ioutil.WriteFile(path.Join(memberDXDir, "config.json"), configBytes, 0755)
-
-		// Copy files into docker volumes
-		volumeName := fmt.Sprintf("%s_dataexchange_%s", s.Stack.Name, member.ID)
-		docker.MkdirInVolume(volumeName, "peer-certs", verbose)
-		docker.CopyFileToVolume(volumeName, path.Join(memberDXDir, "config.json"), "/config.json", verbose)
-		docker.CopyFileToVolume(volumeName, path.Join(memberDXDir, "cert.pem"), "/cert.pem", verbose)
-		docker.CopyFileToVolume(volumeName, path.Join(memberDXDir, "key.pem"), "/key.pem", verbose)
+		// Contracts in different directories can have the same file name
+		deployment.Filename = uniqueFilename(deployment.Filename, filenames)
+		if err := ioutil.WriteFile(filepath.Join(contractsDir, deployment.Filename), contractBytes, 0755); err != nil {
+			return err
+		}
+		s.Stack.Contracts = append(s.Stack.Contracts, deployment)
 	}
 	return nil
 }
 
-func createMember(id string, index int, options *InitOptions, external bool) *types.Member {
-	privateKey, _ := secp256k1.NewPrivateKey(secp256k1.S256())
-	privateKeyBytes := privateKey.Serialize()
-	encodedPrivateKey := "0x" + hex.EncodeToString(privateKeyBytes)
-	// Remove the "04" Suffix byte when computing the address. This byte indicates that it is an uncompressed public key.
-	publicKeyBytes := privateKey.PubKey().SerializeUncompressed()[1:]
-	// Take the hash of the public key to generate the address
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(publicKeyBytes)
-	// Ethereum addresses only use the lower 20 bytes, so toss the rest away
-	encodedAddress := "0x" + hex.EncodeToString(hash.Sum(nil)[12:32])
+func createMember(id string, index int, mnemonic string, options *InitOptions, external bool) (*types.Member, error) {
+	privateKey, err := DeriveMemberKey(mnemonic, index)
+	if err != nil {
+		return nil, err
+	}
+	encodedPrivateKey, encodedAddress := getEncodedKeyAndAddress(privateKey)
 
 	serviceBase := options.ServicesBasePort + (index * 100)
-	return &types.Member{
+	member := &types.Member{
 		ID:                      id,
 		Index:                   &index,
 		Address:                 encodedAddress,
@@ -355,6 +672,17 @@ func createMember(id string, index int, options *InitOptions, external bool) *ty
 		OrgName:                 options.OrgNames[index],
 		NodeName:                options.NodeNames[index],
 	}
+	// The IPFS identity is saved, so the node keeps the same peer ID when the stack is reset
+	if options.SharedStorageProvider == IPFS {
+		if member.IPFSPrivateKey, member.IPFSPeerID, err = DeriveKeyAndPeerId(privateKey); err != nil {
+			return nil, err
+		}
+	}
+	if options.Host != "" {
+		member.ExposedDataexchangeP2PPort = serviceBase + 9
+		member.ExposedIPFSSwarmPort = serviceBase + 10
+	}
+	return member, nil
 }
 
 func (s *StackManager) StartStack(verbose bool, options *StartOptions) error {
@@ -397,6 +725,18 @@ func (s *StackManager) StartStack(verbose bool, options *StartOptions) error {
 func (s *StackManager) PullStack(verbose bool, options *PullOptions) error {
 	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
 
+	// Use docker to pull every image - retry on failure
+	for _, image := range s.getImages() {
+		s.Log.Info(fmt.Sprintf("pulling '%s", image))
+		if err := docker.RunDockerCommandRetry(workingDir, verbose, verbose, options.Retries, "pull", image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getImages returns every image used by the stack that is pulled from a registry
+func (s *StackManager) getImages() []string {
 	var images []string
 
 	// Collect FireFly docker image names
@@ -411,32 +751,116 @@ func (s *StackManager) PullStack(verbose bool, options *PullOptions) error {
 		images = append(images, fullImage)
 	}
 
-	// IPFS is the only one that we always use in every stack
-	images = append(images, constants.IPFSImageName)
-
 	// Also pull postgres if we're using it
 	if s.Stack.Database == PostgreSQL.String() {
 		images = append(images, constants.PostgresImageName)
 	}
+	// SQLite databases are queried with 'ff db'
+	if s.Stack.Database == SQLite3.String() {
+		images = append(images, constants.SQLiteImageName)
+	}
 
 	// Iterate over all images used by the blockchain provider
 	for _, service := range s.blockchainProvider.GetDockerServiceDefinitions() {
 		images = append(images, service.Service.Image)
 	}
+	switch s.Stack.BlockchainProvider {
+	case HyperledgerFabric.String():
+		// Fabric crypto material and channels are created with the Fabric tools, and the peer starts chaincode
+		// containers from the ccenv and baseos images
+		images = append(images, fabric.FabricToolsImageName, fabric.FabricCCEnvImageName, fabric.FabricBaseOSImageName)
+	case GoEthereum.String(), HyperledgerBesu.String(), EthereumRemote.String():
+		// Ethereum contracts are compiled with solc
+		images = append(images, constants.SolcImageName)
+	}
 
 	// Iterate over all images used by the tokens provider
 	for _, service := range s.tokensProvider.GetDockerServiceDefinitions() {
 		images = append(images, service.Service.Image)
 	}
 
-	// Use docker to pull every image - retry on failure
+	// Iterate over all images used by the data exchange provider
+	for _, service := range s.dataExchangeProvider.GetDockerServiceDefinitions() {
+		images = append(images, service.Service.Image)
+	}
+
+	// Iterate over all images used by the shared storage provider
+	for _, service := range s.sharedStorageProvider.GetDockerServiceDefinitions() {
+		images = append(images, service.Service.Image)
+	}
+	// S3 buckets are created with the MinIO client
+	if s.Stack.SharedStorageProvider == S3.String() {
+		images = append(images, constants.MinioClientImageName)
+	}
+
+	// Files are copied into volumes with alpine
+	images = append(images, constants.AlpineImageName)
+	return uniqueImages(images)
+}
+
+// SaveImages writes every image used by the stack to a tar file, so that the stack can be created and started
+// on a machine without access to the registries. Images must have been pulled first
+func (s *StackManager) SaveImages(path string, verbose bool) error {
+	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	images := s.getImages()
+	savedImages := make(map[string]string)
+	for _, entry := range s.Stack.VersionManifest.Entries() {
+		if entry == nil {
+			continue
+		}
+		if entry.Local {
+			// Locally built images aren't pulled, but need to be saved
+			images = append(images, entry.GetDockerImageString())
+		} else if entry.SHA != "" {
+			// Images pinned by digest are saved under a tag made from the digest, as 'docker load' drops digests
+			if err := docker.RunDockerCommand(workingDir, verbose, verbose, "tag", entry.GetDockerImageString(), entry.GetSavedImageString()); err != nil {
+				return err
+			}
+			savedImages[entry.GetDockerImageString()] = entry.GetSavedImageString()
+		}
+	}
+	for i, image := range images {
+		if savedImage, ok := savedImages[image]; ok {
+			images[i] = savedImage
+			image = savedImage
+		}
+		s.Log.Info(fmt.Sprintf("saving '%s'", image))
+	}
+	return docker.RunDockerCommand(workingDir, verbose, verbose, append([]string{"save", "-o", path}, images...)...)
+}
+
+// LoadImages loads the images in a tar file written by SaveImages. Stacks created or upgraded afterwards
+// use the saved tags of the images that are pinned by digest
+func LoadImages(path string, verbose bool) error {
+	return docker.RunDockerCommand(".", verbose, verbose, "load", "-i", path)
+}
+
+// useSavedImages switches the services that use images pinned by digest to the tags the images were saved under
+// by SaveImages. Only the docker compose file changes, so the stack's manifest keeps the digests
+func (s *StackManager) useSavedImages(compose *docker.DockerComposeConfig) {
+	savedImages := make(map[string]string)
+	for _, entry := range s.Stack.VersionManifest.Entries() {
+		if entry != nil && !entry.Local && entry.SHA != "" {
+			savedImages[entry.GetDockerImageString()] = entry.GetSavedImageString()
+		}
+	}
+	for _, service := range compose.Services {
+		if savedImage, ok := savedImages[service.Image]; ok {
+			service.Image = savedImage
+		}
+	}
+}
+
+func uniqueImages(images []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
 	for _, image := range images {
-		s.Log.Info(fmt.Sprintf("pulling '%s", image))
-		if err := docker.RunDockerCommandRetry(workingDir, verbose, verbose, options.Retries, "pull", image); err != nil {
-			return err
+		if !seen[image] {
+			seen[image] = true
+			unique = append(unique, image)
 		}
 	}
-	return nil
+	return unique
 }
 
 func (s *StackManager) removeVolumes(verbose bool) {
@@ -447,6 +871,12 @@ func (s *StackManager) removeVolumes(verbose bool) {
 	for _, service := range s.tokensProvider.GetDockerServiceDefinitions() {
 		volumes = append(volumes, service.VolumeNames...)
 	}
+	for _, service := range s.sharedStorageProvider.GetDockerServiceDefinitions() {
+		volumes = append(volumes, service.VolumeNames...)
+	}
+	for _, service := range s.dataExchangeProvider.GetDockerServiceDefinitions() {
+		volumes = append(volumes, service.VolumeNames...)
+	}
 	for volumeName := range docker.CreateDockerCompose(s.Stack).Volumes {
 		volumes = append(volumes, volumeName)
 	}
@@ -469,6 +899,10 @@ func (s *StackManager) runStartupSequence(workingDir string, verbose bool, first
 		return err
 	}
 
+	if err := s.sharedStorageProvider.PostStart(); err != nil {
+		return err
+	}
+
 	if err := s.ensureFireflyNodesUp(firstTimeSetup); err != nil {
 		return err
 	}
@@ -515,7 +949,17 @@ func (s *StackManager) checkPortsAvailable() error {
 		ports = append(ports, member.ExposedPostgresPort)
 		ports = append(ports, member.ExposedUIPort)
 		ports = append(ports, member.ExposedTokensPort)
+		// Ports for members on other machines are only exposed when the stack has a host
+		if s.Stack.Host != "" {
+			ports = append(ports, member.ExposedDataexchangeP2PPort)
+			ports = append(ports, member.ExposedIPFSSwarmPort)
+		}
 	}
+	if s.Stack.ExposedBlockchainP2PPort != 0 {
+		ports = append(ports, s.Stack.ExposedBlockchainP2PPort)
+	}
+	// Blockchain nodes can publish more ports, such as the ports of each Fabric orderer
+	ports = append(ports, getPublishedPorts(s.blockchainProvider.GetDockerServiceDefinitions())...)
 	for _, port := range ports {
 		available, err := checkPortAvailable(port)
 		if err != nil {
@@ -528,6 +972,24 @@ func (s *StackManager) checkPortsAvailable() error {
 	return nil
 }
 
+// getPublishedPorts returns the host ports of the services' port mappings, which are in the format
+// [<ip>:]<host port>:<container port>
+func getPublishedPorts(serviceDefinitions []*docker.ServiceDefinition) []int {
+	ports := []int{}
+	for _, serviceDefinition := range serviceDefinitions {
+		for _, mapping := range serviceDefinition.Service.Ports {
+			parts := strings.Split(mapping, ":")
+			if len(parts) < 2 {
+				continue
+			}
+			if port, err := strconv.Atoi(parts[len(parts)-2]); err == nil {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
 func checkPortAvailable(port int) (bool, error) {
 	timeout := time.Millisecond * 500
 	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", fmt.Sprint(port)), timeout)
@@ -572,8 +1034,13 @@ func (s *StackManager) runFirstTimeSetup(verbose bool, options *StartOptions) er
 		return err
 	}
 
-	s.Log.Info("writing data exchange certs")
-	if err := s.writeDataExchangeCerts(verbose); err != nil {
+	s.Log.Info("initializing shared storage")
+	if err := s.sharedStorageProvider.FirstTimeSetup(); err != nil {
+		return err
+	}
+
+	s.Log.Info("initializing data exchange")
+	if err := s.dataExchangeProvider.FirstTimeSetup(); err != nil {
 		return err
 	}
 
@@ -598,6 +1065,10 @@ func (s *StackManager) runFirstTimeSetup(verbose bool, options *StartOptions) er
 	if err := s.tokensProvider.DeploySmartContracts(); err != nil {
 		return err
 	}
+	// Save the addresses of any contracts that were deployed
+	if err := s.writeStackConfig(); err != nil {
+		return err
+	}
 
 	if err := s.patchConfigAndRestartFireflyNodes(verbose); err != nil {
 		return err
@@ -667,6 +1138,79 @@ func (s *StackManager) UpgradeStack(verbose bool) error {
 	return docker.RunDockerComposeCommand(workingDir, verbose, verbose, "pull")
 }
 
+// UpgradeVersionManifest switches the stack to the images in a new manifest, and writes the stack's
+// docker-compose.yml again to use them. Changes in docker-compose.override.yml are kept. Contracts keep
+// the names they were registered under, until they are upgraded with UpgradeContracts
+func (s *StackManager) UpgradeVersionManifest(manifest *types.VersionManifest) error {
+	s.Stack.VersionManifest = manifest
+	if err := s.writeDockerCompose(s.buildDockerCompose()); err != nil {
+		return fmt.Errorf("failed to write docker-compose.yml: %s", err)
+	}
+	return s.writeStackConfig()
+}
+
+// IsRunning returns true if any of the stack's containers are running
+func (s *StackManager) IsRunning(verbose bool) (bool, error) {
+	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	containers, err := docker.RunDockerCommandStdout(workingDir, verbose, "ps", "-q", "--filter", "label=com.docker.compose.project="+s.Stack.Name)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(containers) != "", nil
+}
+
+// UpgradeContracts starts an upgraded stack, deploys the FireFly contract or chaincode from the new
+// FireFly image, and migrates every member over to the newly registered contract instance
+func (s *StackManager) UpgradeContracts(verbose bool) error {
+	if hasBeenRun, err := s.StackHasRunBefore(); err != nil {
+		return err
+	} else if !hasBeenRun {
+		return fmt.Errorf("stack '%s' has not been started yet - contracts will be deployed when it is first started", s.Stack.Name)
+	}
+	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
+	if err := s.runStartupSequence(workingDir, verbose, false); err != nil {
+		return err
+	}
+
+	if err := s.blockchainProvider.UpgradeSmartContracts(); err != nil {
+		return err
+	}
+	// Regenerate the member configs so they point to the new contract, and save its address
+	if err := s.writeConfigs(verbose); err != nil {
+		return err
+	}
+
+	// The FireFly chaincode keeps the same name when it is upgraded, so only ethereum members need migrating
+	if s.Stack.BlockchainProvider == HyperledgerFabric.String() || s.Stack.BlockchainProvider == FabricRemote.String() {
+		return nil
+	}
+	instance := "/contracts/" + ethereum.GetFireFlyContractName(s.Stack)
+	for _, member := range s.Stack.Members {
+		s.Log.Info(fmt.Sprintf("migrating %s to contract instance %s", member.ID, instance))
+		configRecordUrl := fmt.Sprintf("http://localhost:%d/admin/api/v1/config/records/blockchain.ethereum.ethconnect.instance", member.ExposedFireflyAdminPort)
+		if err := core.RequestWithRetry("PUT", configRecordUrl, instance, nil); err != nil && err != io.EOF {
+			return err
+		}
+		resetUrl := fmt.Sprintf("http://localhost:%d/admin/api/v1/config/reset", member.ExposedFireflyAdminPort)
+		if err := core.RequestWithRetry("POST", resetUrl, "{}", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *StackManager) DeployContract(filename string, options *types.DeployOptions) (string, error) {
+	if _, err := os.Stat(filename); err != nil {
+		return "", err
+	}
+	result, err := s.blockchainProvider.DeployContract(filename, options)
+	if err != nil {
+		return "", err
+	}
+	// Save the deployed contract, or any chaincode versions that were committed
+	return result, s.writeStackConfig()
+}
+
 func (s *StackManager) PrintStackInfo(verbose bool) error {
 	workingDir := filepath.Join(constants.StacksDir, s.Stack.Name)
 	fmt.Print("\n")
@@ -677,6 +1221,44 @@ func (s *StackManager) PrintStackInfo(verbose bool) error {
 	if err := docker.RunDockerComposeCommand(workingDir, verbose, true, "ps"); err != nil {
 		return err
 	}
+	contracts := s.Stack.Contracts
+	if s.Stack.TokenContract != nil {
+		contracts = append([]*types.ContractDeployment{s.Stack.TokenContract}, contracts...)
+	}
+	if s.Stack.FireFlyContract != nil {
+		contracts = append([]*types.ContractDeployment{s.Stack.FireFlyContract}, contracts...)
+	}
+	if len(contracts) > 0 {
+		fmt.Print("\nContracts:\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tADDRESS\tFILE")
+		for _, contract := range contracts {
+			address := contract.Address
+			if address == "" {
+				address = "(not deployed)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", contract.Name, address, contract.Filename)
+		}
+		w.Flush()
+	}
+	if len(s.Stack.Chaincodes) > 0 {
+		fmt.Print("\nChaincodes:\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCHANNEL\tVERSION\tSEQUENCE\tPACKAGE ID")
+		for _, chaincode := range s.Stack.Chaincodes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", chaincode.Name, chaincode.Channel, chaincode.Version, chaincode.Sequence, chaincode.PackageID)
+		}
+		w.Flush()
+	}
+	if len(s.Stack.RemoteMembers) > 0 {
+		fmt.Print("\nRemote members:\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ORG\tNODE\tADDRESS\tDATA EXCHANGE")
+		for _, member := range s.Stack.RemoteMembers {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", member.OrgName, member.NodeName, member.Address, member.DataExchangeEndpoint)
+		}
+		w.Flush()
+	}
 	fmt.Printf("\nYour docker compose file for this stack can be found at: %s\n\n", filepath.Join(constants.StacksDir, s.Stack.Name, "docker-compose.yml"))
 	return nil
 }
@@ -728,6 +1310,18 @@ func (s *StackManager) getBlockchainProvider(verbose bool) blockchain.IBlockchai
 			Log:     s.Log,
 			Stack:   s.Stack,
 		}
+	case EthereumRemote.String():
+		return &remoterpc.RemoteRPCProvider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
+	case FabricRemote.String():
+		return &fabric.RemoteFabricProvider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
 	default:
 		return nil
 	}
@@ -751,3 +1345,43 @@ func (s *StackManager) getTokensProvider(verbose bool) tokens.ITokensProvider {
 		return nil
 	}
 }
+
+func (s *StackManager) getSharedStorageProvider(verbose bool) sharedstorage.ISharedStorageProvider {
+	switch s.Stack.SharedStorageProvider {
+	// Stacks created before shared storage providers were added always have an IPFS node per member
+	case IPFS.String(), "":
+		return &ipfs.IPFSProvider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
+	case SharedIPFS.String():
+		return &ipfs.SharedIPFSProvider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
+	case S3.String():
+		return &s3.S3Provider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
+	default:
+		return nil
+	}
+}
+
+func (s *StackManager) getDataExchangeProvider(verbose bool) dataexchange.IDataExchangeProvider {
+	switch s.Stack.DataExchangeProvider {
+	// Stacks created before data exchange providers were added always use the HTTPS data exchange
+	case HTTPSDataExchange.String(), "":
+		return &https.HTTPSProvider{
+			Verbose: verbose,
+			Log:     s.Log,
+			Stack:   s.Stack,
+		}
+	default:
+		return nil
+	}
+}
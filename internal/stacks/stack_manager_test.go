@@ -0,0 +1,102 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stacks
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/internal/docker"
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitContractSpec(T *testing.T) {
+	assert.Equal(T, []string{"contracts/Token.json"}, splitContractSpec("contracts/Token.json"))
+	assert.Equal(T, []string{"Token.json", "token", "name=Coin,supply=100"}, splitContractSpec("Token.json:token:name=Coin,supply=100"))
+	assert.Equal(T, []string{"Token.json", "token", "uri=http://localhost:8000"}, splitContractSpec("Token.json:token:uri=http://localhost:8000"))
+	assert.Equal(T, []string{`C:\contracts\Token.json`, "token"}, splitContractSpec(`C:\contracts\Token.json:token`))
+	assert.Equal(T, []string{"C:/contracts/Token.json"}, splitContractSpec("C:/contracts/Token.json"))
+}
+
+func TestSplitContractSelector(T *testing.T) {
+	path, contractName := splitContractSelector("contracts/Tokens.sol#Coin")
+	assert.Equal(T, "contracts/Tokens.sol", path)
+	assert.Equal(T, "Coin", contractName)
+	path, contractName = splitContractSelector("contracts/Token.json")
+	assert.Equal(T, "contracts/Token.json", path)
+	assert.Equal(T, "", contractName)
+}
+
+func TestUniqueFilename(T *testing.T) {
+	used := make(map[string]bool)
+	assert.Equal(T, "Token.json", uniqueFilename("Token.json", used))
+	assert.Equal(T, "Token_2.json", uniqueFilename("Token.json", used))
+	assert.Equal(T, "Token_3.json", uniqueFilename("Token.json", used))
+	assert.Equal(T, "Other.json", uniqueFilename("Other.json", used))
+}
+
+func TestGetPublishedPorts(T *testing.T) {
+	serviceDefinitions := []*docker.ServiceDefinition{
+		{Service: &docker.Service{Ports: []string{"7050:7050", "127.0.0.1:7153:7053"}}},
+		{Service: &docker.Service{Ports: []string{"8545"}}},
+		{Service: &docker.Service{}},
+	}
+	assert.Equal(T, []int{7050, 7153}, getPublishedPorts(serviceDefinitions))
+}
+
+func TestApplyResourceLimits(T *testing.T) {
+	s := &StackManager{Stack: &types.Stack{ResourceProfile: SmallProfile.String()}}
+	compose := &docker.DockerComposeConfig{
+		Services: map[string]*docker.Service{
+			"firefly_core_0": {},
+			"postgres_0":     {},
+			"geth":           {},
+			"ethconnect_0":   {},
+		},
+	}
+	blockchainServices := []*docker.ServiceDefinition{
+		{ServiceName: "geth"},
+		{ServiceName: "ethconnect_0"},
+	}
+	s.applyResourceLimits(compose, blockchainServices)
+	assert.Equal(T, &docker.Service{MemLimit: "512m", CPUs: "0.5", Restart: "on-failure"}, compose.Services["firefly_core_0"])
+	assert.Equal(T, &docker.Service{Restart: "on-failure"}, compose.Services["postgres_0"])
+	assert.Equal(T, &docker.Service{Restart: "on-failure"}, compose.Services["geth"])
+	assert.Equal(T, &docker.Service{Restart: "on-failure"}, compose.Services["ethconnect_0"])
+}
+
+func TestUseSavedImages(T *testing.T) {
+	s := &StackManager{Stack: &types.Stack{
+		VersionManifest: &types.VersionManifest{
+			FireFly:    &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly", Tag: "v0.14.0", SHA: "abc"},
+			Ethconnect: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-ethconnect", Tag: "v3.1.5"},
+		},
+	}}
+	compose := &docker.DockerComposeConfig{
+		Services: map[string]*docker.Service{
+			"firefly_core_0": {Image: "ghcr.io/hyperledger/firefly@sha256:abc"},
+			"ethconnect_0":   {Image: "ghcr.io/hyperledger/firefly-ethconnect:v3.1.5"},
+			"postgres_0":     {Image: "postgres"},
+		},
+	}
+	s.useSavedImages(compose)
+	assert.Equal(T, "ghcr.io/hyperledger/firefly:sha256-abc", compose.Services["firefly_core_0"].Image)
+	assert.Equal(T, "ghcr.io/hyperledger/firefly-ethconnect:v3.1.5", compose.Services["ethconnect_0"].Image)
+	assert.Equal(T, "postgres", compose.Services["postgres_0"].Image)
+	// The manifest keeps the digest
+	assert.Equal(T, "abc", s.Stack.VersionManifest.FireFly.SHA)
+}
@@ -19,6 +19,8 @@ package stacks
 import (
 	"fmt"
 	"strings"
+
+	"github.com/hyperledger/firefly-cli/internal/docker"
 )
 
 type DatabaseSelection int
@@ -50,9 +52,11 @@ const (
 	HyperledgerBesu
 	HyperledgerFabric
 	Corda
+	EthereumRemote
+	FabricRemote
 )
 
-var BlockchainProviderStrings = []string{"geth", "besu", "fabric", "corda"}
+var BlockchainProviderStrings = []string{"geth", "besu", "fabric", "corda", "ethereum-remote", "fabric-remote"}
 
 func (blockchainProvider BlockchainProvider) String() string {
 	return BlockchainProviderStrings[blockchainProvider]
@@ -88,3 +92,102 @@ func TokensProviderFromString(s string) (TokensProvider, error) {
 	}
 	return ERC1155, fmt.Errorf("\"%s\" is not a valid tokens provider selection. valid options are: %v", s, TokensProviderStrings)
 }
+
+type SharedStorageProvider int
+
+const (
+	IPFS SharedStorageProvider = iota
+	SharedIPFS
+	S3
+)
+
+var SharedStorageProviderStrings = []string{"ipfs", "ipfs-shared", "s3"}
+
+func (sharedStorageProvider SharedStorageProvider) String() string {
+	return SharedStorageProviderStrings[sharedStorageProvider]
+}
+
+func SharedStorageProviderFromString(s string) (SharedStorageProvider, error) {
+	for i, sharedStorageProviderSelection := range SharedStorageProviderStrings {
+		if strings.ToLower(s) == sharedStorageProviderSelection {
+			return SharedStorageProvider(i), nil
+		}
+	}
+	return IPFS, fmt.Errorf("\"%s\" is not a valid shared storage provider selection. valid options are: %v", s, SharedStorageProviderStrings)
+}
+
+type DataExchangeProvider int
+
+const (
+	HTTPSDataExchange DataExchangeProvider = iota
+)
+
+var DataExchangeProviderStrings = []string{"https"}
+
+func (dataExchangeProvider DataExchangeProvider) String() string {
+	return DataExchangeProviderStrings[dataExchangeProvider]
+}
+
+func DataExchangeProviderFromString(s string) (DataExchangeProvider, error) {
+	for i, dataExchangeProviderSelection := range DataExchangeProviderStrings {
+		if strings.ToLower(s) == dataExchangeProviderSelection {
+			return DataExchangeProvider(i), nil
+		}
+	}
+	return HTTPSDataExchange, fmt.Errorf("\"%s\" is not a valid data exchange provider selection. valid options are: %v", s, DataExchangeProviderStrings)
+}
+
+type ResourceProfile int
+
+const (
+	DefaultProfile ResourceProfile = iota
+	SmallProfile
+	LargeProfile
+)
+
+var ResourceProfileStrings = []string{"default", "small", "large"}
+
+// ResourceLimits are the limits that a resource profile sets on each type of container. Blockchain nodes and
+// databases hold the stack's state and need more memory as it grows, so they are not limited
+type ResourceLimits struct {
+	Default    *docker.ResourceLimits
+	Blockchain *docker.ResourceLimits
+	Database   *docker.ResourceLimits
+}
+
+// The default profile doesn't limit containers, to match stacks created before profiles were added. The small
+// profile restarts containers that fail, which includes containers killed for running out of memory
+var resourceProfileLimits = []*ResourceLimits{
+	{
+		Default:    &docker.ResourceLimits{},
+		Blockchain: &docker.ResourceLimits{},
+		Database:   &docker.ResourceLimits{},
+	},
+	{
+		Default:    &docker.ResourceLimits{MemLimit: "512m", CPUs: "0.5", Restart: "on-failure"},
+		Blockchain: &docker.ResourceLimits{Restart: "on-failure"},
+		Database:   &docker.ResourceLimits{Restart: "on-failure"},
+	},
+	{
+		Default:    &docker.ResourceLimits{MemLimit: "4g", CPUs: "2", Restart: "unless-stopped"},
+		Blockchain: &docker.ResourceLimits{Restart: "unless-stopped"},
+		Database:   &docker.ResourceLimits{Restart: "unless-stopped"},
+	},
+}
+
+func (resourceProfile ResourceProfile) String() string {
+	return ResourceProfileStrings[resourceProfile]
+}
+
+func (resourceProfile ResourceProfile) Limits() *ResourceLimits {
+	return resourceProfileLimits[resourceProfile]
+}
+
+func ResourceProfileFromString(s string) (ResourceProfile, error) {
+	for i, resourceProfileSelection := range ResourceProfileStrings {
+		if strings.ToLower(s) == resourceProfileSelection {
+			return ResourceProfile(i), nil
+		}
+	}
+	return DefaultProfile, fmt.Errorf("\"%s\" is not a valid resource profile selection. valid options are: %v", s, ResourceProfileStrings)
+}
@@ -49,23 +49,41 @@ func DeployContracts(s *types.Stack, log log.Logger, verbose bool) error {
 		return err
 	}
 
-	var tokenContractAddress string
-	for _, member := range s.Members {
-		if tokenContractAddress == "" {
-			// TODO: version the registered name
-			log.Info(fmt.Sprintf("deploying ERC1155 contract on '%s'", member.ID))
-			tokenContractAddress, err = ethereum.DeployContract(member, tokenContract, "erc1155", map[string]string{"uri": ""})
-			if err != nil {
-				return err
-			}
-		} else {
-			log.Info(fmt.Sprintf("registering ERC1155 contract on '%s'", member.ID))
-			err = ethereum.RegisterContract(member, tokenContract, tokenContractAddress, "erc1155", map[string]string{"uri": ""})
-			if err != nil {
+	name := getContractName(s)
+	// Stacks that joined a network on another machine use the network's token contract
+	address := s.Ethereum.GetTokenContractAddress()
+	if address != "" {
+		for _, member := range s.Members {
+			log.Info(fmt.Sprintf("registering %s contract at %s on '%s'", name, address, member.ID))
+			if err := ethereum.RegisterContract(member, tokenContract, address, name, map[string]string{"uri": ""}); err != nil {
 				return err
 			}
 		}
+	} else if address, err = ethereum.DeployContractToStack(s, log, tokenContract, name, map[string]string{"uri": ""}); err != nil {
+		return err
+	}
+	s.TokenContract = &types.ContractDeployment{
+		Name:     name,
+		Filename: "ERC1155MixedFungible.json",
+		Address:  address,
 	}
-
 	return nil
 }
+
+// NewContractDeployment returns the token contract for a new stack, which is registered under a name
+// that includes the version of the token connector
+func NewContractDeployment(s *types.Stack) *types.ContractDeployment {
+	return &types.ContractDeployment{
+		Name:     ethereum.GetVersionedContractName("erc1155", s.VersionManifest.Tokens),
+		Filename: "ERC1155MixedFungible.json",
+	}
+}
+
+// getContractName returns the name the token contract is registered under in ethconnect, which is saved
+// when the stack is created. Stacks created before the name was recorded always used "erc1155"
+func getContractName(s *types.Stack) string {
+	if s.TokenContract != nil && s.TokenContract.Name != "" {
+		return s.TokenContract.Name
+	}
+	return "erc1155"
+}
@@ -0,0 +1,37 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package erc1155
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContractName(T *testing.T) {
+	stack := &types.Stack{
+		VersionManifest: &types.VersionManifest{
+			Tokens: &types.ManifestEntry{Tag: "v0.10.3"},
+		},
+	}
+	assert.Equal(T, "erc1155", getContractName(stack))
+
+	stack.TokenContract = NewContractDeployment(stack)
+	stack.VersionManifest.Tokens.Tag = "v0.11.0"
+	assert.Equal(T, "erc1155_v0_10_3", getContractName(stack))
+}
@@ -58,7 +58,7 @@ func (p *ERC1155Provider) GetDockerServiceDefinitions() []*docker.ServiceDefinit
 				Ports:         []string{fmt.Sprintf("%d:3000", member.ExposedTokensPort)},
 				Environment: map[string]string{
 					"ETHCONNECT_URL":      p.getEthconnectURL(member),
-					"ETHCONNECT_INSTANCE": "/contracts/erc1155",
+					"ETHCONNECT_INSTANCE": "/contracts/" + getContractName(p.Stack),
 					"ETHCONNECT_IDENTITY": strings.TrimPrefix(member.Address, "0x"),
 					"AUTO_INIT":           "false",
 				},
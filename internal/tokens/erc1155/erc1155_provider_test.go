@@ -0,0 +1,44 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package erc1155
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-cli/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// Upgrading the stack's manifest regenerates the compose file, which must keep using the registered token contract
+func TestGetDockerServiceDefinitionsAfterUpgrade(T *testing.T) {
+	p := &ERC1155Provider{
+		Stack: &types.Stack{
+			Name:    "dev",
+			Members: []*types.Member{{ID: "0", Address: "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"}},
+			VersionManifest: &types.VersionManifest{
+				Tokens: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-tokens-erc1155", Tag: "v0.10.3"},
+			},
+		},
+	}
+	p.Stack.TokenContract = NewContractDeployment(p.Stack)
+	p.Stack.VersionManifest = &types.VersionManifest{
+		Tokens: &types.ManifestEntry{Image: "ghcr.io/hyperledger/firefly-tokens-erc1155", Tag: "v0.11.0"},
+	}
+	service := p.GetDockerServiceDefinitions()[0].Service
+	assert.Equal(T, "ghcr.io/hyperledger/firefly-tokens-erc1155:v0.11.0", service.Image)
+	assert.Equal(T, "/contracts/erc1155_v0_10_3", service.Environment["ETHCONNECT_INSTANCE"])
+}
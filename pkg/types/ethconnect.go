@@ -21,3 +21,10 @@ type Contract struct {
 	ABI          interface{} `json:"abi"`
 	Bytecode     string      `json:"bytecode"`
 }
+
+type ContractDeployment struct {
+	Name     string            `json:"name"`
+	Filename string            `json:"filename"`
+	Args     map[string]string `json:"args,omitempty"`
+	Address  string            `json:"address,omitempty"`
+}
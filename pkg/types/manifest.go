@@ -50,6 +50,12 @@ type ManifestEntry struct {
 	SHA   string `json:"sha,omitempty"`
 }
 
+// GetSavedImageString returns the tag an image pinned by digest is saved under by 'ff images save'. 'docker load'
+// doesn't restore the digests of saved images, so the digest is kept in the tag instead
+func (m *ManifestEntry) GetSavedImageString() string {
+	return fmt.Sprintf("%s:sha256-%s", m.Image, m.SHA)
+}
+
 func (m *ManifestEntry) GetDockerImageString() string {
 	if m.SHA != "" {
 		return fmt.Sprintf("%s@sha256:%s", m.Image, m.SHA)
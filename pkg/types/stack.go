@@ -16,32 +16,233 @@
 
 package types
 
+import "encoding/json"
+
 type Stack struct {
-	Name                  string           `json:"name,omitempty"`
-	Members               []*Member        `json:"members,omitempty"`
-	SwarmKey              string           `json:"swarmKey,omitempty"`
-	ExposedBlockchainPort int              `json:"exposedGethPort,omitempty"`
-	Database              string           `json:"database"`
-	BlockchainProvider    string           `json:"blockchainProvider"`
-	TokensProvider        string           `json:"tokensProvider"`
-	VersionManifest       *VersionManifest `json:"versionManifest,omitempty"`
+	Name                         string                 `json:"name,omitempty"`
+	Members                      []*Member              `json:"members,omitempty"`
+	SwarmKey                     string                 `json:"swarmKey,omitempty"`
+	ExposedBlockchainPort        int                    `json:"exposedGethPort,omitempty"`
+	Database                     string                 `json:"database"`
+	SharedDatabase               bool                   `json:"sharedDatabase,omitempty"`
+	PostgresPassword             string                 `json:"postgresPassword,omitempty"`
+	BlockchainProvider           string                 `json:"blockchainProvider"`
+	TokensProvider               string                 `json:"tokensProvider"`
+	SharedStorageProvider        string                 `json:"sharedStorageProvider,omitempty"`
+	DataExchangeProvider         string                 `json:"dataExchangeProvider,omitempty"`
+	PrepopulateDataExchangePeers bool                   `json:"prepopulateDataExchangePeers,omitempty"`
+	ResourceProfile              string                 `json:"resourceProfile,omitempty"`
+	Network                      string                 `json:"network,omitempty"`
+	UseSavedImages               bool                   `json:"useSavedImages,omitempty"`
+	VersionManifest              *VersionManifest       `json:"versionManifest,omitempty"`
+	Mnemonic                     string                 `json:"mnemonic,omitempty"`
+	Ethereum                     *EthereumOptions       `json:"ethereum,omitempty"`
+	Contracts                    []*ContractDeployment  `json:"contracts,omitempty"`
+	FireFlyContract              *ContractDeployment    `json:"fireflyContract,omitempty"`
+	TokenContract                *ContractDeployment    `json:"tokenContract,omitempty"`
+	Chaincodes                   []*ChaincodeDeployment `json:"chaincodes,omitempty"`
+	Fabric                       *FabricOptions         `json:"fabric,omitempty"`
+	Host                         string                 `json:"host,omitempty"`
+	ExposedBlockchainP2PPort     int                    `json:"exposedBlockchainP2PPort,omitempty"`
+	RemoteMembers                []*RemoteMember        `json:"remoteMembers,omitempty"`
+	FireFlyConfigOverrides       map[string]interface{} `json:"fireflyConfigOverrides,omitempty"`
 }
 
 type Member struct {
-	ID                      string `json:"id,omitempty"`
-	Index                   *int   `json:"index,omitempty"`
-	Address                 string `json:"address,omitempty"`
-	PrivateKey              string `json:"privateKey,omitempty"`
-	ExposedFireflyPort      int    `json:"exposedFireflyPort,omitempty"`
-	ExposedFireflyAdminPort int    `json:"exposedFireflyAdminPort,omitempty"`
-	ExposedConnectorPort    int    `json:"exposedConnectorPort,omitempty"`
-	ExposedPostgresPort     int    `json:"exposedPostgresPort,omitempty"`
-	ExposedDataexchangePort int    `json:"exposedDataexchangePort,omitempty"`
-	ExposedIPFSApiPort      int    `json:"exposedIPFSApiPort,omitempty"`
-	ExposedIPFSGWPort       int    `json:"exposedIPFSGWPort,omitempty"`
-	ExposedUIPort           int    `json:"exposedUiPort,omitempty"`
-	ExposedTokensPort       int    `json:"exposedTokensPort,omitempty"`
-	External                bool   `json:"external,omitempty"`
-	OrgName                 string `json:"orgName,omitempty"`
-	NodeName                string `json:"nodeName,omitempty"`
+	ID                         string                 `json:"id,omitempty"`
+	Index                      *int                   `json:"index,omitempty"`
+	Address                    string                 `json:"address,omitempty"`
+	PrivateKey                 string                 `json:"privateKey,omitempty"`
+	ExposedFireflyPort         int                    `json:"exposedFireflyPort,omitempty"`
+	ExposedFireflyAdminPort    int                    `json:"exposedFireflyAdminPort,omitempty"`
+	ExposedConnectorPort       int                    `json:"exposedConnectorPort,omitempty"`
+	ExposedPostgresPort        int                    `json:"exposedPostgresPort,omitempty"`
+	ExposedDataexchangePort    int                    `json:"exposedDataexchangePort,omitempty"`
+	ExposedIPFSApiPort         int                    `json:"exposedIPFSApiPort,omitempty"`
+	ExposedIPFSGWPort          int                    `json:"exposedIPFSGWPort,omitempty"`
+	ExposedUIPort              int                    `json:"exposedUiPort,omitempty"`
+	ExposedTokensPort          int                    `json:"exposedTokensPort,omitempty"`
+	ExposedDataexchangeP2PPort int                    `json:"exposedDataexchangeP2PPort,omitempty"`
+	ExposedIPFSSwarmPort       int                    `json:"exposedIPFSSwarmPort,omitempty"`
+	IPFSPeerID                 string                 `json:"ipfsPeerId,omitempty"`
+	IPFSPrivateKey             string                 `json:"ipfsPrivateKey,omitempty"`
+	External                   bool                   `json:"external,omitempty"`
+	ConfigOverrides            map[string]interface{} `json:"configOverrides,omitempty"`
+	OrgName                    string                 `json:"orgName,omitempty"`
+	NodeName                   string                 `json:"nodeName,omitempty"`
+}
+
+// RemoteMember is a member of the network whose services run on another machine. Members of a stack
+// on another machine are added when joining that stack's network with a join token
+type RemoteMember struct {
+	OrgName              string `json:"orgName,omitempty"`
+	NodeName             string `json:"nodeName,omitempty"`
+	Address              string `json:"address,omitempty"`
+	DataExchangeEndpoint string `json:"dataExchangeEndpoint,omitempty"`
+	DataExchangeCert     string `json:"dataExchangeCert,omitempty"`
+	IPFSSwarmAddress     string `json:"ipfsSwarmAddress,omitempty"`
+	IPFSPeerID           string `json:"ipfsPeerId,omitempty"`
+	BlockchainRPC        string `json:"blockchainRpc,omitempty"`
+}
+
+type EthereumOptions struct {
+	ChainID                int               `json:"chainId,omitempty"`
+	BlockPeriod            int               `json:"blockPeriod,omitempty"`
+	GasLimit               uint64            `json:"gasLimit,omitempty"`
+	BerlinBlock            *int              `json:"berlinBlock,omitempty"`
+	LondonBlock            *int              `json:"londonBlock,omitempty"`
+	PrefundedAccounts      map[string]string `json:"prefundedAccounts,omitempty"`
+	RPCURL                 string            `json:"rpcUrl,omitempty"`
+	AccountPassword        string            `json:"accountPassword,omitempty"`
+	FireFlyContractAddress string            `json:"fireflyContractAddress,omitempty"`
+	TokenContractAddress   string            `json:"tokenContractAddress,omitempty"`
+	NodeKey                string            `json:"nodeKey,omitempty"`
+	Bootnodes              []string          `json:"bootnodes,omitempty"`
+	Genesis                json.RawMessage   `json:"genesis,omitempty"`
+}
+
+// GetPostgresPassword returns the password of the postgres user. A password is only generated for
+// stacks with a shared database, so other stacks use the password that used to be hard coded
+func (s *Stack) GetPostgresPassword() string {
+	if s.PostgresPassword == "" {
+		return "f1refly"
+	}
+	return s.PostgresPassword
+}
+
+// DefaultChainID is the chain ID of stacks that don't set one
+const DefaultChainID = 2021
+
+// Stacks created before these options existed have no EthereumOptions saved, so each
+// getter falls back to the value that used to be hard coded
+
+func (o *EthereumOptions) GetChainID() int {
+	if o == nil || o.ChainID == 0 {
+		return DefaultChainID
+	}
+	return o.ChainID
+}
+
+func (o *EthereumOptions) GetBlockPeriod() int {
+	if o == nil {
+		return 0
+	}
+	return o.BlockPeriod
+}
+
+func (o *EthereumOptions) GetGasLimit() uint64 {
+	if o == nil || o.GasLimit == 0 {
+		return 0x47b760
+	}
+	return o.GasLimit
+}
+
+func (o *EthereumOptions) GetGasTarget() uint64 {
+	if o == nil || o.GasLimit == 0 {
+		return 804247552
+	}
+	return o.GasLimit
+}
+
+func (o *EthereumOptions) LondonEnabled() bool {
+	return o != nil && o.LondonBlock != nil
+}
+
+// GetAccountPassword returns the password that member keys are imported into a remote node's keystore with
+func (o *EthereumOptions) GetAccountPassword() string {
+	if o == nil || o.AccountPassword == "" {
+		return "correcthorsebatterystaple"
+	}
+	return o.AccountPassword
+}
+
+func (o *EthereumOptions) GetBootnodes() []string {
+	if o == nil {
+		return nil
+	}
+	return o.Bootnodes
+}
+
+// GetFireFlyContractAddress returns the address of an existing FireFly contract to use instead of
+// deploying a new one, or an empty string if the stack deploys its own
+func (o *EthereumOptions) GetFireFlyContractAddress() string {
+	if o == nil {
+		return ""
+	}
+	return o.FireFlyContractAddress
+}
+
+// GetTokenContractAddress returns the address of an existing ERC1155 token contract to use instead of
+// deploying a new one, or an empty string if the stack deploys its own
+func (o *EthereumOptions) GetTokenContractAddress() string {
+	if o == nil {
+		return ""
+	}
+	return o.TokenContractAddress
+}
+
+type FabricOptions struct {
+	Channels          []string `json:"channels,omitempty"`
+	ChaincodeName     string   `json:"chaincodeName,omitempty"`
+	EndorsementPolicy string   `json:"endorsementPolicy,omitempty"`
+	Orderers          int      `json:"orderers,omitempty"`
+	ConnectionProfile string   `json:"connectionProfile,omitempty"`
+	CryptoDir         string   `json:"cryptoDir,omitempty"`
+	Configtx          string   `json:"configtx,omitempty"`
+	GenesisProfile    string   `json:"genesisProfile,omitempty"`
+}
+
+// DefaultGenesisProfile is the profile in the configtx.yaml generated for a stack
+const DefaultGenesisProfile = "SingleOrgApplicationGenesis"
+
+// GetChannels returns every channel in the network. FireFly itself uses the first one
+func (o *FabricOptions) GetChannels() []string {
+	if o == nil || len(o.Channels) == 0 {
+		return []string{"firefly"}
+	}
+	return o.Channels
+}
+
+func (o *FabricOptions) GetChannel() string {
+	return o.GetChannels()[0]
+}
+
+func (o *FabricOptions) GetChaincodeName() string {
+	if o == nil || o.ChaincodeName == "" {
+		return "firefly"
+	}
+	return o.ChaincodeName
+}
+
+// GetGenesisProfile returns the configtx.yaml profile the genesis block of each channel is generated from
+func (o *FabricOptions) GetGenesisProfile() string {
+	if o == nil || o.GenesisProfile == "" {
+		return DefaultGenesisProfile
+	}
+	return o.GenesisProfile
+}
+
+func (o *FabricOptions) GetOrderers() int {
+	if o == nil || o.Orderers < 1 {
+		return 1
+	}
+	return o.Orderers
+}
+
+// GetEndorsementPolicy returns the signature policy for chaincode definitions, or an
+// empty string to use the channel's default endorsement policy
+func (o *FabricOptions) GetEndorsementPolicy() string {
+	if o == nil {
+		return ""
+	}
+	return o.EndorsementPolicy
+}
+
+// DeployOptions are the options of a contract or chaincode deployed to a running stack with 'ff deploy'
+type DeployOptions struct {
+	ContractName string   // Contract to deploy from a Solidity source file
+	Name         string   // Name to register the contract or commit the chaincode with
+	Args         []string // Constructor arguments of a contract
+	SolcBasePath string   // Directory that imports in a Solidity source file are resolved within
+	Channel      string   // Fabric channel to commit the chaincode on
 }